@@ -6,6 +6,11 @@ import (
 	"log/slog"
 	"os"
 
+	// хранилища регистрируются в init() своих пакетов
+	_ "client-services/internal/services"
+	_ "client-services/internal/storage/in-memory"
+	_ "client-services/internal/storage/sqlite"
+
 	"github.com/dikkadev/prettyslog"
 	"github.com/joho/godotenv"
 )
@@ -41,6 +46,21 @@ func main() {
 	slog.Debug("debug messages are enabled")
 	slog.Error("error messaages are enabled")
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := run.Migrate(cfg, log, os.Args[2:]); err != nil {
+			log.Error("migrate failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "promote" {
+		if err := run.Promote(cfg, log, os.Args[2:]); err != nil {
+			log.Error("promote failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
 	run.Run(cfg, log)
 }
 
@@ -0,0 +1,93 @@
+// Package apperr содержит ошибки, общие для хранилищ и резолверов.
+// Код ошибки передается клиенту в extensions.code ответа GraphQL.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Code string
+
+const (
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+	CodeValidation      Code = "VALIDATION"
+	CodeConflict        Code = "CONFLICT"
+	CodeInternal        Code = "INTERNAL"
+)
+
+// Error - ошибка с кодом и сообщением, которое можно показать клиенту
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is сравнивает ошибки по коду, поэтому errors.Is(err, apperr.ErrNotFound)
+// выполняется для любой ошибки с кодом NOT_FOUND, например ErrPostNotFound
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Message == "" && t.Code == e.Code
+}
+
+// ошибки-категории, используются только для сравнения через errors.Is
+var (
+	ErrNotFound        = &Error{Code: CodeNotFound}
+	ErrForbidden       = &Error{Code: CodeForbidden}
+	ErrUnauthenticated = &Error{Code: CodeUnauthenticated}
+	ErrValidation      = &Error{Code: CodeValidation}
+	ErrConflict        = &Error{Code: CodeConflict}
+)
+
+var (
+	ErrPostNotFound    = NotFound("post not found")
+	ErrCommentNotFound = NotFound("comment not found")
+	ErrParentNotFound  = NotFound("parent comment not found")
+	ErrUserNotFound    = NotFound("user not found")
+	ErrCommentDeleted  = Conflict("comment deleted")
+	ErrUserExists      = Conflict("username already taken")
+	ErrInvalidCursor   = Validation("invalid cursor value")
+	ErrAccessDenied    = Forbidden("access denied")
+	ErrAuthRequired    = Unauthenticated("authentication required")
+)
+
+func NotFound(format string, args ...any) *Error {
+	return newError(CodeNotFound, format, args...)
+}
+
+func Forbidden(format string, args ...any) *Error {
+	return newError(CodeForbidden, format, args...)
+}
+
+func Unauthenticated(format string, args ...any) *Error {
+	return newError(CodeUnauthenticated, format, args...)
+}
+
+func Validation(format string, args ...any) *Error {
+	return newError(CodeValidation, format, args...)
+}
+
+func Conflict(format string, args ...any) *Error {
+	return newError(CodeConflict, format, args...)
+}
+
+func newError(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// CodeOf возвращает код первой ошибки Error в цепочке err; CodeInternal, если такой нет
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// User - аутентифицированный пользователь, которого middleware помещает в контекст запроса
+type User struct {
+	ID        string
+	Username  string
+	Role      model.Role
+	ExpiresAt time.Time
+}
+
+// Claims - содержимое токена. Роль фиксируется при выдаче токена,
+// поэтому повышение роли вступает в силу после повторного входа; повышенную роль из токена директивы перепроверяют по хранилищу.
+type Claims struct {
+	Username string     `json:"username"`
+	Role     model.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Manager выпускает и проверяет токены, подписанные HS256
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewManager(cfg *config.Auth) (*Manager, error) {
+	const op = "auth.NewManager"
+
+	if cfg == nil {
+		return nil, fmt.Errorf("%s: auth section is missing in the config file", op)
+	}
+	if cfg.JWTSecret == "" {
+		return nil, fmt.Errorf("%s: jwt secret is not set", op)
+	}
+
+	return &Manager{
+		secret: []byte(cfg.JWTSecret),
+		ttl:    cfg.TokenTTL,
+	}, nil
+}
+
+func (m *Manager) Issue(userID string, username string, role model.Role) (string, time.Time, error) {
+	const op = "auth.Issue"
+
+	expiresAt := time.Now().Add(m.ttl)
+	claims := &Claims{
+		Username: username,
+		Role:     role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: failed to sign token: %w", op, err)
+	}
+
+	return token, expiresAt, nil
+}
+
+func (m *Manager) Parse(token string) (*User, error) {
+	const op = "auth.Parse"
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+	if !claims.Role.IsValid() {
+		return nil, fmt.Errorf("%s: %w: unknown role %q", op, ErrInvalidToken, claims.Role)
+	}
+
+	return &User{
+		ID:        claims.Subject,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+type userKey struct{}
+
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+func UserFromContext(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(userKey{}).(*User)
+	return u, ok
+}
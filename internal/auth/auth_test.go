@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManager(t *testing.T) {
+	_, err := NewManager(nil)
+	require.ErrorContains(t, err, "auth section is missing")
+
+	_, err = NewManager(&config.Auth{TokenTTL: time.Hour})
+	require.ErrorContains(t, err, "jwt secret is not set")
+
+	manager, err := NewManager(&config.Auth{JWTSecret: "test-secret", TokenTTL: time.Hour})
+	require.NoError(t, err)
+
+	token, _, err := manager.Issue("user-id", "alice", model.RoleUser)
+	require.NoError(t, err)
+	user, err := manager.Parse(token)
+	require.NoError(t, err)
+	require.Equal(t, "user-id", user.ID)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log/slog"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -13,7 +14,17 @@ type Config struct {
 	Storage        string          `yaml:"storage" env-default:"in-memory"`
 	QueryCache     int             `yaml:"query-cache" env-default:"100"`
 	StorageConnect *StorageConnect `yaml:"storage_connect"`
-	HTTPServer     *HTTPServer     `yaml:"http_server"`
+	InMemory       InMemory        `yaml:"in_memory"`
+	SQLite         SQLite          `yaml:"sqlite"`
+	// параметры сторонних хранилищ, подключенных через реестр storage
+	StorageOptions map[string]string `yaml:"storage_options"`
+	StorageCache   StorageCache      `yaml:"storage_cache"`
+	HTTPServer     *HTTPServer       `yaml:"http_server"`
+	Notifications  Notifications     `yaml:"notifications"`
+	// Auth - тоже значение: cleanenv не читает переменные окружения во вложенных указателях,
+	// а секрет JWT_SECRET задается именно окружением
+	Auth    Auth    `yaml:"auth"`
+	Metrics Metrics `yaml:"metrics"`
 }
 
 type StorageConnect struct {
@@ -24,6 +35,62 @@ type StorageConnect struct {
 	SQLPort     string `yaml:"sql_port" env-default:"5432"`
 	SQLDBName   string `yaml:"sql_dbname" env-default:"client-service"`
 	SQLSSLMode  string `yaml:"sql_sslmode" env-default:"disable"`
+	// CA-сертификат для проверки сервера в режимах verify-ca и verify-full
+	SQLSSLRootCert string `yaml:"sql_sslrootcert" env:"SQL_SSLROOTCERT"`
+
+	ApplicationName string `yaml:"application_name" env-default:"client-services"`
+	// 0 - значения по умолчанию go-pg
+	PoolSize         int           `yaml:"pool_size" env-default:"0"`
+	MinIdleConns     int           `yaml:"min_idle_conns" env-default:"0"`
+	MaxConnAge       time.Duration `yaml:"max_conn_age" env-default:"0s"`
+	DialTimeout      time.Duration `yaml:"dial_timeout" env-default:"5s"`
+	ReadTimeout      time.Duration `yaml:"read_timeout" env-default:"0s"`
+	WriteTimeout     time.Duration `yaml:"write_timeout" env-default:"0s"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env-default:"0s"`
+
+	// реплики для запросов только на чтение; учетные данные и параметры подключения берутся у основной базы
+	Replicas []Replica `yaml:"replicas"`
+	// после записи чтения в рамках того же запроса выполняются на основной базе
+	ReadYourWrites Bool `yaml:"read_your_writes" env-default:"true"`
+
+	// повтор транзакций при временных ошибках: число попыток и границы паузы между ними
+	RetryMaxAttempts int           `yaml:"retry_max_attempts" env-default:"5"`
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay" env-default:"50ms"`
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay" env-default:"2s"`
+}
+
+type Replica struct {
+	Address string `yaml:"address"`
+	// пустое значение - порт основной базы
+	Port string `yaml:"port"`
+}
+
+// InMemory - сохранение хранилища in-memory на диск.
+// Блок значением, чтобы без секции in_memory действовали значения по умолчанию и переменная IN_MEMORY_DIR
+type InMemory struct {
+	// каталог журнала и снимков; пустое значение - данные хранятся только в памяти
+	Dir string `yaml:"dir" env:"IN_MEMORY_DIR"`
+	// always - fsync после каждой записи, everysec - раз в секунду, never - сброс на диск выполняет ОС
+	Fsync            string        `yaml:"fsync" env-default:"everysec"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env-default:"5m"`
+}
+
+// SQLite - блок значением, чтобы без секции sqlite действовали путь по умолчанию и переменная SQLITE_PATH
+type SQLite struct {
+	Path string `yaml:"path" env:"SQLITE_PATH" env-default:"data/client-services.db"`
+	// время ожидания блокировки базы другим соединением
+	BusyTimeout time.Duration `yaml:"busy_timeout" env-default:"5s"`
+}
+
+// StorageCache - кэш постов и первых страниц комментариев перед хранилищем.
+// Блок значением, чтобы при одном enabled: true действовали размеры и TTL по умолчанию
+type StorageCache struct {
+	Enabled bool `yaml:"enabled" env:"STORAGE_CACHE_ENABLED" env-default:"false"`
+	// максимальное число постов и страниц комментариев в кэше
+	Posts int `yaml:"posts" env-default:"10000"`
+	Pages int `yaml:"pages" env-default:"10000"`
+	// время жизни записи; ограничивает устаревание данных, измененных другими экземплярами сервиса
+	TTL time.Duration `yaml:"ttl" env-default:"30s"`
 }
 
 type HTTPServer struct {
@@ -33,6 +100,56 @@ type HTTPServer struct {
 	Idle_timeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
 
+// Notifications - блок значением, а не указателем: без секции notifications в файле действуют значения по умолчанию
+type Notifications struct {
+	Backend    string `yaml:"backend" env-default:"local"`
+	BufferSize int    `yaml:"buffer_size" env-default:"16"`
+}
+
+// Metrics - метрики Prometheus. Блок значением, чтобы без секции metrics метрики были включены по умолчанию
+type Metrics struct {
+	Enabled Bool   `yaml:"enabled" env:"METRICS_ENABLED" env-default:"true"`
+	Path    string `yaml:"path" env-default:"/metrics"`
+}
+
+type Auth struct {
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET"`
+	TokenTTL  time.Duration `yaml:"token_ttl" env-default:"24h"`
+}
+
+// Bool - логический параметр со значением по умолчанию true.
+// cleanenv подставляет env-default во все нулевые поля, поэтому явное false у обычного bool заменилось бы на true;
+// нулевое значение Bool означает, что параметр не задан, а false хранится отдельно.
+type Bool uint8
+
+const (
+	boolUnset Bool = iota
+	boolFalse
+	boolTrue
+)
+
+// NewBool возвращает заданное значение параметра
+func NewBool(v bool) Bool {
+	if v {
+		return boolTrue
+	}
+	return boolFalse
+}
+
+func (b *Bool) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseBool(string(text))
+	if err != nil {
+		return err
+	}
+	*b = NewBool(v)
+	return nil
+}
+
+// Value возвращает значение параметра; незаданный параметр - false
+func (b Bool) Value() bool {
+	return b == boolTrue
+}
+
 func MustLoad() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -48,9 +165,8 @@ func MustLoad() *Config {
 		os.Exit(1)
 	}
 
-	var cfg Config
-
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+	cfg, err := load(configPath)
+	if err != nil {
 		slog.Error("failed to read config file",
 			slog.String("path", configPath),
 			slog.Any("error", err),
@@ -58,5 +174,23 @@ func MustLoad() *Config {
 		os.Exit(1)
 	}
 
-	return &cfg
+	return cfg
+}
+
+func load(configPath string) (*Config, error) {
+	var cfg Config
+
+	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+		return nil, err
+	}
+
+	// cleanenv не заходит во вложенные указатели: переменные окружения и значения по умолчанию
+	// для секции storage_connect применяются отдельно, если она есть в файле
+	if cfg.StorageConnect != nil {
+		if err := cleanenv.ReadEnv(cfg.StorageConnect); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cfg, nil
 }
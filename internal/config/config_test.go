@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_NotificationsDefaults(t *testing.T) {
+	// файл без секции notifications
+	cfg, err := load(writeConfig(t, "env: \"local\"\nstorage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "local", cfg.Notifications.Backend)
+	require.Equal(t, 16, cfg.Notifications.BufferSize)
+
+	cfg, err = load(writeConfig(t, "notifications:\n  backend: \"postgres\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "postgres", cfg.Notifications.Backend)
+	require.Equal(t, 16, cfg.Notifications.BufferSize)
+}
+
+func TestLoad_AuthFromEnv(t *testing.T) {
+	t.Setenv("JWT_SECRET", "env-secret")
+
+	// секрет берется из окружения и без секции auth в файле
+	cfg, err := load(writeConfig(t, "env: \"local\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "env-secret", cfg.Auth.JWTSecret)
+	require.Equal(t, 24*time.Hour, cfg.Auth.TokenTTL)
+
+	cfg, err = load(writeConfig(t, "auth:\n  token_ttl: \"1h\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "env-secret", cfg.Auth.JWTSecret)
+	require.Equal(t, time.Hour, cfg.Auth.TokenTTL)
+}
+
+func TestLoad_RetryDefaults(t *testing.T) {
+	// секция storage_connect без параметров повтора
+	cfg, err := load(writeConfig(t, "storage: \"postgres\"\nstorage_connect:\n  sql_address: \"db\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "db", cfg.StorageConnect.SQLAddress)
+	require.Equal(t, 5, cfg.StorageConnect.RetryMaxAttempts)
+	require.Equal(t, 50*time.Millisecond, cfg.StorageConnect.RetryBaseDelay)
+	require.Equal(t, 2*time.Second, cfg.StorageConnect.RetryMaxDelay)
+
+	cfg, err = load(writeConfig(t, "storage_connect:\n  retry_max_attempts: 2\n  retry_max_delay: \"1s\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, 2, cfg.StorageConnect.RetryMaxAttempts)
+	require.Equal(t, 50*time.Millisecond, cfg.StorageConnect.RetryBaseDelay)
+	require.Equal(t, time.Second, cfg.StorageConnect.RetryMaxDelay)
+
+	// без секции storage_connect значения по умолчанию не создают ее
+	cfg, err = load(writeConfig(t, "storage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.Nil(t, cfg.StorageConnect)
+}
+
+func TestLoad_StorageConnectDefaults(t *testing.T) {
+	t.Setenv("SQL_SSLROOTCERT", "/etc/ssl/root.crt")
+
+	cfg, err := load(writeConfig(t, "storage_connect:\n  sql_address: \"db\"\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.StorageConnect.ReadYourWrites.Value())
+	require.Equal(t, "postgres", cfg.StorageConnect.SQLUser)
+	require.Equal(t, "5432", cfg.StorageConnect.SQLPort)
+	require.Equal(t, "disable", cfg.StorageConnect.SQLSSLMode)
+	require.Equal(t, "/etc/ssl/root.crt", cfg.StorageConnect.SQLSSLRootCert)
+	require.Equal(t, "client-services", cfg.StorageConnect.ApplicationName)
+	require.Equal(t, 5*time.Second, cfg.StorageConnect.DialTimeout)
+	require.Zero(t, cfg.StorageConnect.PoolSize)
+	require.Zero(t, cfg.StorageConnect.ReadTimeout)
+
+	// явное false не заменяется значением по умолчанию
+	cfg, err = load(writeConfig(t, "storage_connect:\n  read_your_writes: false\n  pool_size: 20\n  dial_timeout: \"1s\"\n"))
+	require.NoError(t, err)
+	require.False(t, cfg.StorageConnect.ReadYourWrites.Value())
+	require.Equal(t, 20, cfg.StorageConnect.PoolSize)
+	require.Equal(t, time.Second, cfg.StorageConnect.DialTimeout)
+
+	_, err = load(writeConfig(t, "storage_connect:\n  read_your_writes: \"sometimes\"\n"))
+	require.Error(t, err)
+}
+
+func TestLoad_InMemoryDefaults(t *testing.T) {
+	cfg, err := load(writeConfig(t, "in_memory:\n  dir: \"./data\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "./data", cfg.InMemory.Dir)
+	require.Equal(t, "everysec", cfg.InMemory.Fsync)
+	require.Equal(t, 5*time.Minute, cfg.InMemory.SnapshotInterval)
+
+	// каталог задается окружением и без секции in_memory
+	t.Setenv("IN_MEMORY_DIR", "/var/lib/client-services")
+	cfg, err = load(writeConfig(t, "storage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/client-services", cfg.InMemory.Dir)
+	require.Equal(t, "everysec", cfg.InMemory.Fsync)
+	require.Equal(t, 5*time.Minute, cfg.InMemory.SnapshotInterval)
+}
+
+func TestLoad_SQLiteDefaults(t *testing.T) {
+	cfg, err := load(writeConfig(t, "storage: \"sqlite\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "data/client-services.db", cfg.SQLite.Path)
+	require.Equal(t, 5*time.Second, cfg.SQLite.BusyTimeout)
+
+	t.Setenv("SQLITE_PATH", "/var/lib/client-services/db.sqlite")
+	cfg, err = load(writeConfig(t, "sqlite:\n  busy_timeout: \"1s\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/client-services/db.sqlite", cfg.SQLite.Path)
+	require.Equal(t, time.Second, cfg.SQLite.BusyTimeout)
+}
+
+func TestLoad_StorageCacheDefaults(t *testing.T) {
+	// одного enabled: true достаточно: размеры и TTL берутся по умолчанию
+	cfg, err := load(writeConfig(t, "storage_cache:\n  enabled: true\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.StorageCache.Enabled)
+	require.Equal(t, 10000, cfg.StorageCache.Posts)
+	require.Equal(t, 10000, cfg.StorageCache.Pages)
+	require.Equal(t, 30*time.Second, cfg.StorageCache.TTL)
+
+	cfg, err = load(writeConfig(t, "storage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.False(t, cfg.StorageCache.Enabled)
+
+	t.Setenv("STORAGE_CACHE_ENABLED", "true")
+	cfg, err = load(writeConfig(t, "storage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.StorageCache.Enabled)
+	require.Equal(t, 30*time.Second, cfg.StorageCache.TTL)
+}
+
+func TestLoad_MetricsDefaults(t *testing.T) {
+	// без секции metrics метрики включены по адресу по умолчанию
+	cfg, err := load(writeConfig(t, "storage: \"in-memory\"\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.Metrics.Enabled.Value())
+	require.Equal(t, "/metrics", cfg.Metrics.Path)
+
+	cfg, err = load(writeConfig(t, "metrics:\n  enabled: true\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.Metrics.Enabled.Value())
+	require.Equal(t, "/metrics", cfg.Metrics.Path)
+
+	// явное false не заменяется значением по умолчанию
+	cfg, err = load(writeConfig(t, "metrics:\n  enabled: false\n"))
+	require.NoError(t, err)
+	require.False(t, cfg.Metrics.Enabled.Value())
+
+	t.Setenv("METRICS_ENABLED", "false")
+	cfg, err = load(writeConfig(t, "metrics:\n  path: \"/internal/metrics\"\n"))
+	require.NoError(t, err)
+	require.False(t, cfg.Metrics.Enabled.Value())
+	require.Equal(t, "/internal/metrics", cfg.Metrics.Path)
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// maxThreadReplies ограничивает число ответов на один комментарий, загружаемых вместе с веткой
+const maxThreadReplies = 20
+
+// loadComments загружает страницу комментариев поста.
+// При maxDepth в странице только корневые комментарии, а ответы до глубины maxDepth загружаются вместе с ними:
+// на каждый комментарий не больше first ответов и не больше maxThreadReplies, остальные - через поле replies с курсором.
+func (r *Resolver) loadComments(ctx context.Context, postID string, first *int32, after *string, maxDepth *int32, withTotal bool) (*model.CommentConnection, error) {
+	const op = "graph.comments.loadComments"
+
+	m := r.UqMutex.GetMutex(postID)
+	m.RLock()
+	defer m.RUnlock()
+	if after != nil {
+		err := r.Comment_.IsCommentExist(ctx, *after, postID)
+		if err != nil {
+			r.Log.Info("failed to find cursor",
+				slog.String("op", op),
+				slog.String("cursor", *after),
+				slog.String("error", err.Error()),
+			)
+			if errors.Is(err, apperr.ErrNotFound) {
+				return nil, fmt.Errorf("%s: %w", op, apperr.ErrInvalidCursor)
+			}
+			return nil, fmt.Errorf("failed to find cursor: %w", err)
+		}
+	}
+
+	var comments *[]model.Comment
+	var hasNextPage bool
+	var newCursor string
+	var err error
+	if loaders := loadersFrom(ctx); loaders != nil && after == nil && maxDepth == nil {
+		// первые страницы комментариев для всех постов запроса загружаются одним пакетом
+		var page *model.CommentsPage
+		page, err = loaders.Comments.Load(ctx, commentsKey{postID: postID, first: *first})
+		if err == nil {
+			comments, hasNextPage, newCursor = &page.Comments, page.HasNextPage, page.EndCursor
+		}
+	} else if maxDepth == nil {
+		comments, hasNextPage, newCursor, err = r.Comment_.GetComments(ctx, first, after, postID)
+	} else {
+		comments, hasNextPage, newCursor, err = r.Comment_.GetReplies(ctx, first, after, postID, nil)
+	}
+	if err != nil {
+		r.Log.Error("failed to get comments",
+			slog.String("op", op),
+			slog.String("postID", postID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	comConnection := newCommentConnection(*comments, hasNextPage, newCursor)
+
+	if maxDepth != nil && *maxDepth > 0 {
+		rootIDs := make([]string, 0, len(*comments))
+		for _, c := range *comments {
+			rootIDs = append(rootIDs, c.ID)
+		}
+
+		limit := min(*first, maxThreadReplies)
+		thread, err := r.Comment_.GetThread(ctx, postID, rootIDs, int(*maxDepth), limit)
+		if err != nil {
+			r.Log.Error("failed to get comments thread",
+				slog.String("op", op),
+				slog.String("postID", postID),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("failed to get comments thread: %w", err)
+		}
+
+		attachThread(comConnection, thread, int(*maxDepth), limit)
+	}
+
+	if withTotal {
+		total, err := r.Comment_.CountComments(ctx, postID)
+		if err != nil {
+			r.Log.Error("failed to count comments",
+				slog.String("op", op),
+				slog.String("postID", postID),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("failed to count comments: %w", err)
+		}
+		comConnection.TotalCount = &total
+	}
+
+	return comConnection, nil
+}
@@ -0,0 +1,105 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchFunc загружает значения сразу для нескольких ключей.
+// Ключи, отсутствующие в результате, получают нулевое значение.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader собирает ключи, запрошенные в течение wait, и загружает их одним вызовом BatchFunc.
+// Загрузчик рассчитан на один запрос: результаты кэшируются до конца его жизни.
+type Loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+	cache map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	timer   *time.Timer
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func New[K comparable, V any](ctx context.Context, fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]*batch[K, V]),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b, ok := l.cache[key]
+	if !ok {
+		if l.batch == nil {
+			l.batch = &batch[K, V]{done: make(chan struct{})}
+			current := l.batch
+			current.timer = time.AfterFunc(l.wait, func() { l.dispatch(current) })
+		}
+		b = l.batch
+		b.keys = append(b.keys, key)
+		l.cache[key] = b
+
+		// заполненный пакет отсоединяется сразу, чтобы следующие ключи попали уже в новый
+		if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+			l.batch = nil
+			b.timer.Stop()
+			go l.run(b)
+		}
+	}
+	l.mu.Unlock()
+
+	var zero V
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return zero, b.err
+		}
+		return b.results[key], nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch выполняет пакет по таймеру, если он еще не отправлен при достижении maxBatch
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+// run загружает пакет и всегда освобождает ожидающих.
+// Паника в BatchFunc возвращается им как ошибка, иначе они зависли бы навсегда
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	const op = "graph.dataloader.run"
+
+	defer close(b.done)
+	defer func() {
+		if r := recover(); r != nil {
+			b.results, b.err = nil, fmt.Errorf("%s: batch function panicked: %v", op, r)
+		}
+	}()
+
+	b.results, b.err = l.fetch(l.ctx, b.keys)
+}
@@ -0,0 +1,137 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recorder запоминает ключи каждого вызова BatchFunc
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) fetch(ctx context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]int(nil), keys...))
+	r.mu.Unlock()
+
+	result := make(map[int]string, len(keys))
+	for _, key := range keys {
+		result[key] = string(rune('a' + key))
+	}
+	return result, nil
+}
+
+func (r *recorder) calls() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batches
+}
+
+// loadAll запрашивает ключи параллельно и возвращает результаты в порядке ключей
+func loadAll(l *Loader[int, string], keys []int) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+
+	return values, errs
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	rec := &recorder{}
+	l := New(context.Background(), rec.fetch, time.Minute, 3)
+
+	values, errs := loadAll(l, []int{0, 1, 2, 3, 4, 5})
+	for i, err := range errs {
+		require.NoError(t, err)
+		require.Equal(t, string(rune('a'+i)), values[i])
+	}
+
+	calls := rec.calls()
+	require.Len(t, calls, 2)
+	require.Len(t, calls[0], 3)
+	require.Len(t, calls[1], 3)
+	require.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5}, append(calls[0], calls[1]...))
+}
+
+func TestLoader_Wait(t *testing.T) {
+	rec := &recorder{}
+	l := New(context.Background(), rec.fetch, time.Millisecond, 100)
+
+	value, err := l.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, "b", value)
+	require.Equal(t, [][]int{{1}}, rec.calls())
+}
+
+func TestLoader_DuplicateKeys(t *testing.T) {
+	rec := &recorder{}
+	l := New(context.Background(), rec.fetch, time.Minute, 2)
+
+	values, errs := loadAll(l, []int{1, 1, 1, 2})
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"b", "b", "b", "c"}, values)
+
+	calls := rec.calls()
+	require.Len(t, calls, 1)
+	require.ElementsMatch(t, []int{1, 2}, calls[0])
+
+	// повторный запрос берется из кэша
+	value, err := l.Load(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, "c", value)
+	require.Len(t, rec.calls(), 1)
+}
+
+func TestLoader_FetchError(t *testing.T) {
+	fetchErr := errors.New("connection refused")
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, fetchErr
+	}, time.Minute, 2)
+
+	_, errs := loadAll(l, []int{1, 2})
+	for _, err := range errs {
+		require.ErrorIs(t, err, fetchErr)
+	}
+}
+
+func TestLoader_FetchPanic(t *testing.T) {
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		panic("boom")
+	}, time.Minute, 2)
+
+	_, errs := loadAll(l, []int{1, 2})
+	for _, err := range errs {
+		require.ErrorContains(t, err, "batch function panicked: boom")
+	}
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	rec := &recorder{}
+	l := New(context.Background(), rec.fetch, time.Minute, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// пакет не заполнен и таймер не сработал: ждать результата не нужно
+	_, err := l.Load(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, rec.calls())
+}
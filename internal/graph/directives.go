@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// NewDirectives возвращает реализации директив схемы для graph.Config
+func NewDirectives(r *Resolver) DirectiveRoot {
+	return DirectiveRoot{
+		HasRole: r.hasRole,
+		IsOwner: r.isOwner,
+	}
+}
+
+var roleRank = map[model.Role]int{
+	model.RoleUser:      1,
+	model.RoleModerator: 2,
+	model.RoleAdmin:     3,
+}
+
+func roleAtLeast(have model.Role, want model.Role) bool {
+	return roleRank[have] >= roleRank[want]
+}
+
+// currentRole возвращает роль пользователя из хранилища, а не из токена,
+// поэтому понижение роли через setUserRole действует сразу, не дожидаясь истечения токена.
+// Роль USER не перепроверяется: повышение роли по-прежнему вступает в силу после повторного входа.
+func (r *Resolver) currentRole(ctx context.Context, user *auth.User) (model.Role, error) {
+	if user.Role == model.RoleUser {
+		return user.Role, nil
+	}
+
+	var u *model.User
+	if loaders := loadersFrom(ctx); loaders != nil {
+		var err error
+		u, err = loaders.Users.Load(ctx, user.ID)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		users, err := r.User_.GetUsers(ctx, []string{user.ID})
+		if err != nil {
+			return "", err
+		}
+		u = users[user.ID]
+	}
+	if u == nil {
+		return "", apperr.ErrUserNotFound
+	}
+
+	return u.Role, nil
+}
+
+// hasElevatedRole проверяет, что текущая роль пользователя не ниже role
+func (r *Resolver) hasElevatedRole(ctx context.Context, user *auth.User, role model.Role) (bool, error) {
+	if !roleAtLeast(user.Role, role) {
+		return false, nil
+	}
+	current, err := r.currentRole(ctx, user)
+	if err != nil {
+		if errors.Is(err, apperr.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return roleAtLeast(current, role), nil
+}
+
+func (r *Resolver) hasRole(ctx context.Context, obj any, next graphql.Resolver, role model.Role) (any, error) {
+	const op = "graph.directives.hasRole"
+
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, apperr.ErrAuthRequired
+	}
+	allowed, err := r.hasElevatedRole(ctx, user, role)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to check role: %w", op, err)
+	}
+	if !allowed {
+		return nil, apperr.ErrAccessDenied
+	}
+
+	return next(ctx)
+}
+
+// isOwner пропускает автора поста или комментария, а также модераторов и администраторов.
+// Посты и комментарии без автора могут изменять только модераторы.
+func (r *Resolver) isOwner(ctx context.Context, obj any, next graphql.Resolver, resource model.OwnedResource, idArg string) (any, error) {
+	const op = "graph.directives.isOwner"
+
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, apperr.ErrAuthRequired
+	}
+	moderator, err := r.hasElevatedRole(ctx, user, model.RoleModerator)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to check role: %w", op, err)
+	}
+	if moderator {
+		return next(ctx)
+	}
+
+	id, ok := graphql.GetFieldContext(ctx).Args[idArg].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: argument %q is missing", op, idArg)
+	}
+
+	authorID, err := r.resourceAuthor(ctx, resource, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to check owner: %w", op, err)
+	}
+	if authorID == nil || *authorID != user.ID {
+		return nil, apperr.ErrAccessDenied
+	}
+
+	return next(ctx)
+}
+
+// canAccessPrivate проверяет, что пользователь из контекста - автор закрытого поста или модератор.
+// Остальным закрытый пост не выдается в запросах, его комментарии и уведомления недоступны
+func (r *Resolver) canAccessPrivate(ctx context.Context, post *model.Post) bool {
+	const op = "graph.directives.canAccessPrivate"
+
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return false
+	}
+	if post.AuthorID != nil && *post.AuthorID == user.ID {
+		return true
+	}
+
+	moderator, err := r.hasElevatedRole(ctx, user, model.RoleModerator)
+	if err != nil {
+		// при ошибке хранилища закрытый пост не выдается
+		r.Log.Error("failed to check role",
+			slog.String("op", op),
+			slog.String("userID", user.ID),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	return moderator
+}
+
+func (r *Resolver) resourceAuthor(ctx context.Context, resource model.OwnedResource, id string) (*string, error) {
+	switch resource {
+	case model.OwnedResourcePost:
+		post, err := r.Post_.GetPost(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return post.AuthorID, nil
+	case model.OwnedResourceComment:
+		comment, err := r.Comment_.GetComment(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return comment.AuthorID, nil
+	default:
+		return nil, fmt.Errorf("unknown resource %q", resource)
+	}
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// NewErrorPresenter возвращает ErrorPresenter, который передает клиенту сообщение ошибки apperr
+// и ее код в extensions.code. Остальные ошибки резолверов логируются целиком,
+// а клиент получает только код INTERNAL без подробностей.
+func NewErrorPresenter(log *slog.Logger) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, e error) *gqlerror.Error {
+		const op = "graph.errors.presentError"
+
+		err := graphql.DefaultErrorPresenter(ctx, e)
+
+		var appErr *apperr.Error
+		if errors.As(e, &appErr) {
+			err.Message = appErr.Message
+			setCode(err, appErr.Code)
+			return err
+		}
+
+		// собственные ошибки gqlgen не оборачивают другую ошибку, их сообщения предназначены клиенту;
+		// ошибки резолверов gqlgen передает сюда обернутыми в gqlerror.Error с заполненным Err
+		var gqlErr *gqlerror.Error
+		if errors.As(e, &gqlErr) && gqlErr.Err == nil {
+			return err
+		}
+
+		log.Error("internal error",
+			slog.String("op", op),
+			slog.String("path", err.Path.String()),
+			slog.String("error", e.Error()),
+		)
+		err.Message = "internal error"
+		setCode(err, apperr.CodeInternal)
+		return err
+	}
+}
+
+func setCode(err *gqlerror.Error, code apperr.Code) {
+	if err.Extensions == nil {
+		err.Extensions = make(map[string]any)
+	}
+	err.Extensions["code"] = string(code)
+}
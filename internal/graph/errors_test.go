@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	presenter := NewErrorPresenter(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	tests := []struct {
+		tErr     error
+		tMessage string
+		tCode    any
+	}{
+		{
+			fmt.Errorf("graph.schema.resolvers.UpdatePost: failed to update post: %w", apperr.ErrPostNotFound),
+			"post not found",
+			"NOT_FOUND",
+		},
+		{
+			&gqlerror.Error{Message: "wrapped", Err: fmt.Errorf("op: %w", apperr.Validation("title cannot be empty"))},
+			"title cannot be empty",
+			"VALIDATION",
+		},
+		{
+			apperr.ErrAccessDenied,
+			"access denied",
+			"FORBIDDEN",
+		},
+		{
+			&gqlerror.Error{Message: "wrapped", Err: errors.New("pg: connection refused")},
+			"internal error",
+			"INTERNAL",
+		},
+		{
+			gqlerror.Errorf("unknown field"),
+			"unknown field",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		err := presenter(context.Background(), tt.tErr)
+
+		require.Equal(t, tt.tMessage, err.Message)
+		require.Equal(t, tt.tCode, err.Extensions["code"])
+	}
+}
+
+func TestAppErrorIs(t *testing.T) {
+	err := fmt.Errorf("services.posts.GetPost: %w", apperr.ErrPostNotFound)
+
+	require.True(t, errors.Is(err, apperr.ErrPostNotFound))
+	require.True(t, errors.Is(err, apperr.ErrNotFound))
+	require.False(t, errors.Is(err, apperr.ErrCommentNotFound))
+	require.False(t, errors.Is(err, apperr.ErrConflict))
+	require.Equal(t, apperr.CodeNotFound, apperr.CodeOf(err))
+	require.Equal(t, apperr.CodeInternal, apperr.CodeOf(errors.New("unexpected")))
+}
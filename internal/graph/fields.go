@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// isFieldRequested проверяет, выбрано ли в запросе вложенное поле текущего поля по пути path
+func isFieldRequested(ctx context.Context, path ...string) bool {
+	if !graphql.HasOperationContext(ctx) {
+		return false
+	}
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return false
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+	selections := fc.Field.Selections
+	for _, name := range path {
+		found := false
+		for _, f := range graphql.CollectFields(opCtx, selections, nil) {
+			if f.Name == name {
+				selections = f.Selections
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"client-services/internal/graph/dataloader"
+	"client-services/internal/graph/model"
+	"context"
+	"time"
+)
+
+// Параметры пакетирования; переменные, чтобы тесты могли сделать сборку пакета детерминированной
+var (
+	loaderWait     = time.Millisecond
+	loaderMaxBatch = 100
+)
+
+type loadersKey struct{}
+
+// Loaders - загрузчики, общие для всех резолверов одного запроса
+type Loaders struct {
+	Comments *dataloader.Loader[commentsKey, *model.CommentsPage]
+	Users    *dataloader.Loader[string, *model.User]
+}
+
+type commentsKey struct {
+	postID string
+	first  int32
+}
+
+// WithLoaders добавляет в контекст загрузчики для одного запроса
+func WithLoaders(ctx context.Context, r *Resolver) context.Context {
+	loaders := &Loaders{
+		Comments: dataloader.New(ctx, r.batchComments, loaderWait, loaderMaxBatch),
+		Users:    dataloader.New(ctx, r.batchUsers, loaderWait, loaderMaxBatch),
+	}
+
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return loaders
+}
+
+// batchUsers загружает авторов постов и комментариев одним запросом
+func (r *Resolver) batchUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	return r.User_.GetUsers(ctx, ids)
+}
+
+// batchComments загружает первые страницы комментариев сразу для нескольких постов
+func (r *Resolver) batchComments(ctx context.Context, keys []commentsKey) (map[commentsKey]*model.CommentsPage, error) {
+	postIDs := make(map[int32][]string)
+	for _, key := range keys {
+		postIDs[key.first] = append(postIDs[key.first], key.postID)
+	}
+
+	result := make(map[commentsKey]*model.CommentsPage, len(keys))
+	for first, ids := range postIDs {
+		pages, err := r.Comment_.GetCommentsBatch(ctx, first, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			page, ok := pages[id]
+			if !ok {
+				page = &model.CommentsPage{}
+			}
+			result[commentsKey{postID: id, first: first}] = page
+		}
+	}
+
+	return result, nil
+}
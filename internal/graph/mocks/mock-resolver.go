@@ -73,6 +73,20 @@ func (m *MockPostInterface) EXPECT() *MockPostInterfaceMockRecorder {
 	return m.recorder
 }
 
+// DeletePost mocks base method.
+func (m *MockPostInterface) DeletePost(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockPostInterfaceMockRecorder) DeletePost(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostInterface)(nil).DeletePost), ctx, id)
+}
+
 // GetAllPosts mocks base method.
 func (m *MockPostInterface) GetAllPosts(ctx context.Context) ([]model.Post, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +117,23 @@ func (mr *MockPostInterfaceMockRecorder) GetPost(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostInterface)(nil).GetPost), ctx, id)
 }
 
+// GetPosts mocks base method.
+func (m *MockPostInterface) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPosts", ctx, first, after, desc)
+	ret0, _ := ret[0].(*[]model.Post)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetPosts indicates an expected call of GetPosts.
+func (mr *MockPostInterfaceMockRecorder) GetPosts(ctx, first, after, desc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostInterface)(nil).GetPosts), ctx, first, after, desc)
+}
+
 // SavePost mocks base method.
 func (m *MockPostInterface) SavePost(ctx context.Context, p *model.Post) (string, time.Time, error) {
 	m.ctrl.T.Helper()
@@ -119,6 +150,36 @@ func (mr *MockPostInterfaceMockRecorder) SavePost(ctx, p interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePost", reflect.TypeOf((*MockPostInterface)(nil).SavePost), ctx, p)
 }
 
+// SetCommentsAllowed mocks base method.
+func (m *MockPostInterface) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCommentsAllowed", ctx, id, allowed)
+	ret0, _ := ret[0].(*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCommentsAllowed indicates an expected call of SetCommentsAllowed.
+func (mr *MockPostInterfaceMockRecorder) SetCommentsAllowed(ctx, id, allowed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommentsAllowed", reflect.TypeOf((*MockPostInterface)(nil).SetCommentsAllowed), ctx, id, allowed)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostInterface) UpdatePost(ctx context.Context, id string, title, content *string) (*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", ctx, id, title, content)
+	ret0, _ := ret[0].(*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostInterfaceMockRecorder) UpdatePost(ctx, id, title, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostInterface)(nil).UpdatePost), ctx, id, title, content)
+}
+
 // MockCommentInterface is a mock of CommentInterface interface.
 type MockCommentInterface struct {
 	ctrl     *gomock.Controller
@@ -142,6 +203,51 @@ func (m *MockCommentInterface) EXPECT() *MockCommentInterfaceMockRecorder {
 	return m.recorder
 }
 
+// CountComments mocks base method.
+func (m *MockCommentInterface) CountComments(ctx context.Context, postID string) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountComments", ctx, postID)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountComments indicates an expected call of CountComments.
+func (mr *MockCommentInterfaceMockRecorder) CountComments(ctx, postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountComments", reflect.TypeOf((*MockCommentInterface)(nil).CountComments), ctx, postID)
+}
+
+// DeleteComment mocks base method.
+func (m *MockCommentInterface) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", ctx, id)
+	ret0, _ := ret[0].(*model.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentInterfaceMockRecorder) DeleteComment(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentInterface)(nil).DeleteComment), ctx, id)
+}
+
+// GetComment mocks base method.
+func (m *MockCommentInterface) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComment", ctx, id)
+	ret0, _ := ret[0].(*model.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComment indicates an expected call of GetComment.
+func (mr *MockCommentInterfaceMockRecorder) GetComment(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComment", reflect.TypeOf((*MockCommentInterface)(nil).GetComment), ctx, id)
+}
+
 // GetComments mocks base method.
 func (m *MockCommentInterface) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +265,53 @@ func (mr *MockCommentInterfaceMockRecorder) GetComments(ctx, first, after, postI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComments", reflect.TypeOf((*MockCommentInterface)(nil).GetComments), ctx, first, after, postID)
 }
 
+// GetCommentsBatch mocks base method.
+func (m *MockCommentInterface) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsBatch", ctx, first, postIDs)
+	ret0, _ := ret[0].(map[string]*model.CommentsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsBatch indicates an expected call of GetCommentsBatch.
+func (mr *MockCommentInterfaceMockRecorder) GetCommentsBatch(ctx, first, postIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsBatch", reflect.TypeOf((*MockCommentInterface)(nil).GetCommentsBatch), ctx, first, postIDs)
+}
+
+// GetReplies mocks base method.
+func (m *MockCommentInterface) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplies", ctx, first, after, postID, parentID)
+	ret0, _ := ret[0].(*[]model.Comment)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetReplies indicates an expected call of GetReplies.
+func (mr *MockCommentInterfaceMockRecorder) GetReplies(ctx, first, after, postID, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplies", reflect.TypeOf((*MockCommentInterface)(nil).GetReplies), ctx, first, after, postID, parentID)
+}
+
+// GetThread mocks base method.
+func (m *MockCommentInterface) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThread", ctx, postID, rootIDs, maxDepth, limit)
+	ret0, _ := ret[0].([]model.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThread indicates an expected call of GetThread.
+func (mr *MockCommentInterfaceMockRecorder) GetThread(ctx, postID, rootIDs, maxDepth, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThread", reflect.TypeOf((*MockCommentInterface)(nil).GetThread), ctx, postID, rootIDs, maxDepth, limit)
+}
+
 // IsCommentExist mocks base method.
 func (m *MockCommentInterface) IsCommentExist(ctx context.Context, commentID, postID string) error {
 	m.ctrl.T.Helper()
@@ -188,3 +341,155 @@ func (mr *MockCommentInterfaceMockRecorder) SaveComment(ctx, c interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveComment", reflect.TypeOf((*MockCommentInterface)(nil).SaveComment), ctx, c)
 }
+
+// UpdateComment mocks base method.
+func (m *MockCommentInterface) UpdateComment(ctx context.Context, id, content string) (*model.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", ctx, id, content)
+	ret0, _ := ret[0].(*model.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentInterfaceMockRecorder) UpdateComment(ctx, id, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockCommentInterface)(nil).UpdateComment), ctx, id, content)
+}
+
+// MockNotifierInterface is a mock of NotifierInterface interface.
+type MockNotifierInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierInterfaceMockRecorder
+}
+
+// MockNotifierInterfaceMockRecorder is the mock recorder for MockNotifierInterface.
+type MockNotifierInterfaceMockRecorder struct {
+	mock *MockNotifierInterface
+}
+
+// NewMockNotifierInterface creates a new mock instance.
+func NewMockNotifierInterface(ctrl *gomock.Controller) *MockNotifierInterface {
+	mock := &MockNotifierInterface{ctrl: ctrl}
+	mock.recorder = &MockNotifierInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifierInterface) EXPECT() *MockNotifierInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockNotifierInterface) Publish(ctx context.Context, n *model.CommentNotify) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockNotifierInterfaceMockRecorder) Publish(ctx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockNotifierInterface)(nil).Publish), ctx, n)
+}
+
+// Subscribe mocks base method.
+func (m *MockNotifierInterface) Subscribe(ctx context.Context, postID string) (<-chan *model.CommentNotify, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, postID)
+	ret0, _ := ret[0].(<-chan *model.CommentNotify)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotifierInterfaceMockRecorder) Subscribe(ctx, postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotifierInterface)(nil).Subscribe), ctx, postID)
+}
+
+// MockUserInterface is a mock of UserInterface interface.
+type MockUserInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserInterfaceMockRecorder
+}
+
+// MockUserInterfaceMockRecorder is the mock recorder for MockUserInterface.
+type MockUserInterfaceMockRecorder struct {
+	mock *MockUserInterface
+}
+
+// NewMockUserInterface creates a new mock instance.
+func NewMockUserInterface(ctrl *gomock.Controller) *MockUserInterface {
+	mock := &MockUserInterface{ctrl: ctrl}
+	mock.recorder = &MockUserInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserInterface) EXPECT() *MockUserInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetUserByName mocks base method.
+func (m *MockUserInterface) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByName", ctx, username)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserByName indicates an expected call of GetUserByName.
+func (mr *MockUserInterfaceMockRecorder) GetUserByName(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByName", reflect.TypeOf((*MockUserInterface)(nil).GetUserByName), ctx, username)
+}
+
+// GetUsers mocks base method.
+func (m *MockUserInterface) GetUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, ids)
+	ret0, _ := ret[0].(map[string]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserInterfaceMockRecorder) GetUsers(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserInterface)(nil).GetUsers), ctx, ids)
+}
+
+// SaveUser mocks base method.
+func (m *MockUserInterface) SaveUser(ctx context.Context, u *model.User, passwordHash string) (string, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, u, passwordHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockUserInterfaceMockRecorder) SaveUser(ctx, u, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserInterface)(nil).SaveUser), ctx, u, passwordHash)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserInterface) SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, id, role)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserInterfaceMockRecorder) SetUserRole(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserInterface)(nil).SetUserRole), ctx, id, role)
+}
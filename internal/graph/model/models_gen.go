@@ -3,15 +3,30 @@
 package model
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
+type AuthPayload struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      *User     `json:"user"`
+}
+
 type Comment struct {
-	ID        string    `json:"id"`
-	PostID    string    `json:"postID"`
-	ParentID  *string   `json:"parentID,omitempty"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string             `json:"id"`
+	PostID    string             `json:"postID"`
+	ParentID  *string            `json:"parentID,omitempty"`
+	AuthorID  *string            `json:"authorID,omitempty"`
+	Author    *User              `json:"author,omitempty" pg:"-"`
+	Content   string             `json:"content"`
+	CreatedAt time.Time          `json:"createdAt"`
+	EditedAt  *time.Time         `json:"editedAt,omitempty"`
+	DeletedAt *time.Time         `json:"deletedAt,omitempty"`
+	Replies   *CommentConnection `json:"replies" pg:"-"`
 }
 
 type CommentConnection struct {
@@ -26,9 +41,11 @@ type CommentEdge struct {
 }
 
 type CommentNotify struct {
-	PostID  string `json:"postID"`
-	ID      string `json:"id"`
-	Content string `json:"content"`
+	PostID          string      `json:"postID"`
+	ID              string      `json:"id"`
+	Content         string      `json:"content"`
+	Event           NotifyEvent `json:"event"`
+	CommentsAllowed *bool       `json:"commentsAllowed,omitempty"`
 }
 
 type Mutation struct {
@@ -41,11 +58,25 @@ type PageInfo struct {
 
 type Post struct {
 	ID              string             `json:"id"`
+	AuthorID        *string            `json:"authorID,omitempty"`
+	Author          *User              `json:"author,omitempty" pg:"-"`
 	Title           string             `json:"title"`
 	Content         string             `json:"content"`
-	Comments        *CommentConnection `json:"comments"`
+	Comments        *CommentConnection `json:"comments" pg:"-"`
 	CommentsAllowed bool               `json:"commentsAllowed"`
+	Private         bool               `json:"private"`
 	CreatedAt       time.Time          `json:"createdAt"`
+	EditedAt        *time.Time         `json:"editedAt,omitempty"`
+}
+
+type PostConnection struct {
+	Edges    []*PostEdge `json:"edges,omitempty"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
 }
 
 type Query struct {
@@ -53,3 +84,232 @@ type Query struct {
 
 type Subscription struct {
 }
+
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type NotifyEvent string
+
+const (
+	NotifyEventCommentAdded           NotifyEvent = "COMMENT_ADDED"
+	NotifyEventCommentsAllowedChanged NotifyEvent = "COMMENTS_ALLOWED_CHANGED"
+)
+
+var AllNotifyEvent = []NotifyEvent{
+	NotifyEventCommentAdded,
+	NotifyEventCommentsAllowedChanged,
+}
+
+func (e NotifyEvent) IsValid() bool {
+	switch e {
+	case NotifyEventCommentAdded, NotifyEventCommentsAllowedChanged:
+		return true
+	}
+	return false
+}
+
+func (e NotifyEvent) String() string {
+	return string(e)
+}
+
+func (e *NotifyEvent) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotifyEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotifyEvent", str)
+	}
+	return nil
+}
+
+func (e NotifyEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotifyEvent) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotifyEvent) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OwnedResource string
+
+const (
+	OwnedResourcePost    OwnedResource = "POST"
+	OwnedResourceComment OwnedResource = "COMMENT"
+)
+
+var AllOwnedResource = []OwnedResource{
+	OwnedResourcePost,
+	OwnedResourceComment,
+}
+
+func (e OwnedResource) IsValid() bool {
+	switch e {
+	case OwnedResourcePost, OwnedResourceComment:
+		return true
+	}
+	return false
+}
+
+func (e OwnedResource) String() string {
+	return string(e)
+}
+
+func (e *OwnedResource) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OwnedResource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OwnedResource", str)
+	}
+	return nil
+}
+
+func (e OwnedResource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OwnedResource) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OwnedResource) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PostOrder string
+
+const (
+	PostOrderCreatedAtAsc  PostOrder = "CREATED_AT_ASC"
+	PostOrderCreatedAtDesc PostOrder = "CREATED_AT_DESC"
+)
+
+var AllPostOrder = []PostOrder{
+	PostOrderCreatedAtAsc,
+	PostOrderCreatedAtDesc,
+}
+
+func (e PostOrder) IsValid() bool {
+	switch e {
+	case PostOrderCreatedAtAsc, PostOrderCreatedAtDesc:
+		return true
+	}
+	return false
+}
+
+func (e PostOrder) String() string {
+	return string(e)
+}
+
+func (e *PostOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostOrder", str)
+	}
+	return nil
+}
+
+func (e PostOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PostOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PostOrder) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleUser      Role = "USER"
+	RoleModerator Role = "MODERATOR"
+	RoleAdmin     Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleUser,
+	RoleModerator,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,8 @@
+package model
+
+// CommentsPage - страница комментариев одного поста, результат пакетной загрузки
+type CommentsPage struct {
+	Comments    []Comment
+	HasNextPage bool
+	EndCursor   string
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"client-services/internal/auth"
 	"client-services/internal/graph/model"
 	uqmutex "client-services/internal/graph/unique-mutex"
 	"context"
@@ -17,8 +18,11 @@ type Resolver struct {
 	Storage  StorageInterface
 	Post_    PostInterface
 	Comment_ CommentInterface
+	User_    UserInterface
 
-	CommentAdded chan *model.CommentNotify
+	Auth *auth.Manager
+
+	Notifier NotifierInterface
 
 	UqMutex *uqmutex.UqMutex
 }
@@ -31,10 +35,33 @@ type PostInterface interface {
 	SavePost(ctx context.Context, p *model.Post) (string, time.Time, error)
 	GetPost(ctx context.Context, id string) (*model.Post, error)
 	GetAllPosts(ctx context.Context) ([]model.Post, error)
+	GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error)
+	UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error)
+	DeletePost(ctx context.Context, id string) error
+	SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error)
 }
 
 type CommentInterface interface {
 	SaveComment(ctx context.Context, c *model.Comment) (string, time.Time, error)
 	GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error)
+	GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error)
+	GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error)
+	GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error)
+	CountComments(ctx context.Context, postID string) (int32, error)
 	IsCommentExist(ctx context.Context, commentID string, postID string) error
+	GetComment(ctx context.Context, id string) (*model.Comment, error)
+	UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, id string) (*model.Comment, error)
+}
+
+type NotifierInterface interface {
+	Publish(ctx context.Context, n *model.CommentNotify) error
+	Subscribe(ctx context.Context, postID string) (<-chan *model.CommentNotify, error)
+}
+
+type UserInterface interface {
+	SaveUser(ctx context.Context, u *model.User, passwordHash string) (string, time.Time, error)
+	GetUserByName(ctx context.Context, username string) (*model.User, string, error)
+	GetUsers(ctx context.Context, ids []string) (map[string]*model.User, error)
+	SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error)
 }
@@ -1,17 +1,23 @@
 package graph
 
 import (
+	"client-services/internal/apperr"
 	"client-services/internal/graph/mocks"
 	"client-services/internal/graph/model"
 	uniquemutex "client-services/internal/graph/unique-mutex"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -35,14 +41,29 @@ func TestResolverCreateComment_Success(t *testing.T) {
 	}
 
 	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
-	mockComment.EXPECT().IsCommentExist(gomock.Any(), parentID, postID).Return(nil)
 
-	mockComment.EXPECT().SaveComment(gomock.Any(), gomock.Any()).Return("id-0", tTime, nil)
+	mockComment.EXPECT().
+		SaveComment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, c *model.Comment) (string, time.Time, error) {
+			require.Equal(t, &parentID, c.ParentID)
+			return "id-0", tTime, nil
+		})
+
+	mockNotifier := mocks.NewMockNotifierInterface(ctrl)
+	mockNotifier.EXPECT().
+		Publish(gomock.Any(), &model.CommentNotify{
+			PostID:  postID,
+			ID:      "id-0",
+			Content: "Content",
+			Event:   model.NotifyEventCommentAdded,
+		}).
+		Return(nil)
 
 	resolver := &Resolver{
 		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
 		Post_:    mockPost,
 		Comment_: mockComment,
+		Notifier: mockNotifier,
 		UqMutex:  uniquemutex.NewUqMutex(),
 	}
 
@@ -83,13 +104,12 @@ func TestResolverCreateComment_Failed(t *testing.T) {
 		errReturn error
 		errWant   string
 	}{
-		{errReturn: errors.New("this comment from another post"), errWant: "parrent comment from another post"},
-		{errReturn: errors.New("not found"), errWant: "parrent comment not found"},
-		{errReturn: errors.New("unexpected error"), errWant: "failed to find parent comment"},
+		{errReturn: fmt.Errorf("storage.in-memory.SaveComment: %w", apperr.ErrParentNotFound), errWant: "parrent comment not found"},
+		{errReturn: errors.New("unexpected error"), errWant: "failed to save comment"},
 	}
 
 	for i := 0; i < len(tests); i++ {
-		mockComment.EXPECT().IsCommentExist(gomock.Any(), parentID, postID).Return(tests[i].errReturn)
+		mockComment.EXPECT().SaveComment(gomock.Any(), gomock.Any()).Return("", time.Time{}, tests[i].errReturn)
 
 		comment, err := resolver.Mutation().CreateComment(context.Background(), &parentID, postID, "Content")
 		require.Nil(t, comment)
@@ -130,3 +150,269 @@ func TestResolverCreateComment_Failed_2(t *testing.T) {
 	_, err = resolver.Mutation().CreateComment(context.Background(), &parentID, postID, tStr)
 	require.ErrorContains(t, err, "text must have 2000 chars or less")
 }
+
+func TestResolverUpdateComment(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	commentID := "c-0"
+	mockComment.EXPECT().UpdateComment(gomock.Any(), commentID, "New content").Return(
+		&model.Comment{ID: commentID, PostID: "p-0", Content: "New content", CreatedAt: tTime, EditedAt: &tTime}, nil)
+	mockComment.EXPECT().UpdateComment(gomock.Any(), "c-1", "New content").Return(nil, errors.New("comment deleted"))
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Comment_: mockComment,
+	}
+
+	comment, err := resolver.Mutation().UpdateComment(context.Background(), commentID, "New content")
+	require.NoError(t, err)
+	require.Equal(t, "New content", comment.Content)
+	require.Equal(t, &tTime, comment.EditedAt)
+
+	comment, err = resolver.Mutation().UpdateComment(context.Background(), "c-1", "New content")
+	require.ErrorContains(t, err, "comment deleted")
+	require.Nil(t, comment)
+
+	_, err = resolver.Mutation().UpdateComment(context.Background(), commentID, " ")
+	require.ErrorContains(t, err, "content cannot be empty")
+	_, err = resolver.Mutation().UpdateComment(context.Background(), commentID, strings.Repeat("t", 2001))
+	require.ErrorContains(t, err, "text must have 2000 chars or less")
+}
+
+func TestResolverDeleteComment(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	commentID := "c-0"
+	mockComment.EXPECT().DeleteComment(gomock.Any(), commentID).Return(
+		&model.Comment{ID: commentID, PostID: "p-0", CreatedAt: tTime, DeletedAt: &tTime}, nil)
+	mockComment.EXPECT().DeleteComment(gomock.Any(), "c-1").Return(nil, errors.New("comment not found"))
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Comment_: mockComment,
+	}
+
+	comment, err := resolver.Mutation().DeleteComment(context.Background(), commentID)
+	require.NoError(t, err)
+	require.Empty(t, comment.Content)
+	require.Equal(t, &tTime, comment.DeletedAt)
+
+	comment, err = resolver.Mutation().DeleteComment(context.Background(), "c-1")
+	require.ErrorContains(t, err, "comment not found")
+	require.Nil(t, comment)
+}
+
+func TestResolverGetPost_Thread(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	postID := "p-0"
+	rootID, replyID, deepID := "c-0", "c-1", "c-2"
+
+	roots := []model.Comment{{ID: rootID, PostID: postID, Content: "root", CreatedAt: tTime}}
+	thread := []model.Comment{
+		{ID: replyID, PostID: postID, ParentID: &rootID, Content: "reply", CreatedAt: tTime},
+		{ID: deepID, PostID: postID, ParentID: &replyID, Content: "deep", CreatedAt: tTime},
+	}
+
+	maxDepth := int32(2)
+	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(&model.Post{ID: postID}, nil)
+	mockComment.EXPECT().GetReplies(gomock.Any(), gomock.Any(), nil, postID, nil).Return(&roots, false, rootID, nil)
+	mockComment.EXPECT().GetThread(gomock.Any(), postID, []string{rootID}, int(maxDepth), int32(5)).Return(thread, nil)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+
+	first := int32(5)
+	post, err := resolver.Query().GetPost(context.Background(), postID, &first, nil, &maxDepth)
+	require.NoError(t, err)
+	require.Len(t, post.Comments.Edges, 1)
+
+	root := post.Comments.Edges[0].Node
+	require.Equal(t, rootID, root.ID)
+	require.Len(t, root.Replies.Edges, 1)
+
+	reply := root.Replies.Edges[0].Node
+	require.Equal(t, replyID, reply.ID)
+	require.Len(t, reply.Replies.Edges, 1)
+
+	deep := reply.Replies.Edges[0].Node
+	require.Equal(t, deepID, deep.ID)
+	require.Nil(t, deep.Replies)
+
+	// ответы, загруженные вместе с постом, не запрашиваются повторно
+	replies, err := resolver.Comment().Replies(context.Background(), root, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, root.Replies, replies)
+
+	// ответы глубже maxDepth загружаются по запросу
+	mockComment.EXPECT().GetReplies(gomock.Any(), &first, nil, postID, &deepID).Return(&[]model.Comment{}, false, "", nil)
+	replies, err = resolver.Comment().Replies(context.Background(), deep, &first, nil)
+	require.NoError(t, err)
+	require.Empty(t, replies.Edges)
+	require.False(t, replies.PageInfo.HasNextPage)
+}
+
+func TestResolverGetPost_ThreadLimit(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	postID := "p-0"
+	rootID := "c-0"
+	roots := []model.Comment{{ID: rootID, PostID: postID, Content: "root", CreatedAt: tTime}}
+	thread := []model.Comment{
+		{ID: "c-1", PostID: postID, ParentID: &rootID, Content: "first", CreatedAt: tTime},
+		{ID: "c-2", PostID: postID, ParentID: &rootID, Content: "second", CreatedAt: tTime},
+	}
+
+	maxDepth := int32(1)
+	first := int32(1)
+	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(&model.Post{ID: postID}, nil)
+	mockComment.EXPECT().GetReplies(gomock.Any(), &first, nil, postID, nil).Return(&roots, false, rootID, nil)
+	// хранилище возвращает на один ответ больше страницы
+	mockComment.EXPECT().GetThread(gomock.Any(), postID, []string{rootID}, int(maxDepth), first).Return(thread, nil)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+
+	post, err := resolver.Query().GetPost(context.Background(), postID, &first, nil, &maxDepth)
+	require.NoError(t, err)
+
+	root := post.Comments.Edges[0].Node
+	require.Len(t, root.Replies.Edges, 1)
+	require.Equal(t, "c-1", root.Replies.Edges[0].Node.ID)
+	require.True(t, root.Replies.PageInfo.HasNextPage)
+	require.Equal(t, "c-1", *root.Replies.PageInfo.EndCursor)
+
+	// следующая страница неполных предзагруженных ответов читается из хранилища
+	after := "c-1"
+	next := []model.Comment{thread[1]}
+	mockComment.EXPECT().GetReplies(gomock.Any(), &first, &after, postID, &rootID).Return(&next, false, "c-2", nil)
+	replies, err := resolver.Comment().Replies(context.Background(), root, &first, &after)
+	require.NoError(t, err)
+	require.Len(t, replies.Edges, 1)
+	require.Equal(t, "c-2", replies.Edges[0].Node.ID)
+	require.False(t, replies.PageInfo.HasNextPage)
+}
+
+func TestResolverReplies_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Comment_: mockComment,
+	}
+
+	comment := &model.Comment{ID: "c-0", PostID: "p-0"}
+
+	_, err := resolver.Comment().Replies(context.Background(), comment, nil, nil)
+	require.ErrorContains(t, err, "parameter `first` is missing")
+
+	first := int32(-1)
+	_, err = resolver.Comment().Replies(context.Background(), comment, &first, nil)
+	require.ErrorContains(t, err, "`first` cannot be less than 0")
+
+	maxDepth := int32(-1)
+	first = 1
+	_, err = resolver.Query().GetPost(context.Background(), "p-0", &first, nil, &maxDepth)
+	require.ErrorContains(t, err, "`maxDepth` cannot be less than 0")
+}
+
+func TestResolverGetPost_TotalCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	postID := "p-0"
+	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(&model.Post{ID: postID}, nil).Times(2)
+	mockComment.EXPECT().GetCommentsBatch(gomock.Any(), int32(1), []string{postID}).
+		Return(map[string]*model.CommentsPage{postID: {}}, nil).Times(2)
+	mockComment.EXPECT().CountComments(gomock.Any(), postID).Return(int32(42), nil).Times(1)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+	c := newTestClient(resolver)
+
+	var withCount struct {
+		GetPost struct {
+			Comments struct {
+				TotalCount *int32
+			}
+		}
+	}
+	err := c.Post(`query { getPost(id: "p-0", first: 1) { comments { totalCount } } }`, &withCount)
+	require.NoError(t, err)
+	require.NotNil(t, withCount.GetPost.Comments.TotalCount)
+	require.Equal(t, int32(42), *withCount.GetPost.Comments.TotalCount)
+
+	var withoutCount struct {
+		GetPost struct {
+			ID       string
+			Comments struct {
+				PageInfo struct {
+					HasNextPage bool
+				}
+			}
+		}
+	}
+	err = c.Post(`query { getPost(id: "p-0", first: 1) { id comments { pageInfo { hasNextPage } } } }`, &withoutCount)
+	require.NoError(t, err)
+	require.Equal(t, postID, withoutCount.GetPost.ID)
+}
+
+func newTestClient(resolver *Resolver) *client.Client {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver, Directives: NewDirectives(resolver)}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(NewErrorPresenter(resolver.Log))
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(WithLoaders(ctx, resolver))
+	})
+
+	return client.New(srv)
+}
+
+// batchExactly отправляет пакет загрузчика ровно после n разных ключей, а не по таймеру
+func batchExactly(t *testing.T, n int) {
+	t.Helper()
+
+	wait, maxBatch := loaderWait, loaderMaxBatch
+	loaderWait, loaderMaxBatch = time.Minute, n
+	t.Cleanup(func() { loaderWait, loaderMaxBatch = wait, maxBatch })
+}
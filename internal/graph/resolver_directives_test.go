@@ -0,0 +1,230 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
+	"client-services/internal/graph/mocks"
+	"client-services/internal/graph/model"
+	uniquemutex "client-services/internal/graph/unique-mutex"
+	"client-services/internal/notify"
+	"client-services/internal/storage/postgres"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func asUser(id string, role model.Role) client.Option {
+	return func(bd *client.Request) {
+		bd.HTTP = bd.HTTP.WithContext(auth.WithUser(bd.HTTP.Context(), &auth.User{ID: id, Role: role}))
+	}
+}
+
+func TestDirectiveHasRole_CreatePost(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		SavePost(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, p *model.Post) (string, time.Time, error) {
+			require.NotNil(t, p.AuthorID)
+			require.Equal(t, "user-id", *p.AuthorID)
+			return "post-id", tTime, nil
+		}).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+	}
+	c := newTestClient(resolver)
+
+	const query = `mutation { createPost(title: "Title", content: "Content", commentsAllowed: true) { id } }`
+	var resp struct {
+		CreatePost struct {
+			ID string
+		}
+	}
+
+	err := c.Post(query, &resp)
+	require.ErrorContains(t, err, "authentication required")
+
+	err = c.Post(query, &resp, asUser("user-id", model.RoleUser))
+	require.NoError(t, err)
+	require.Equal(t, "post-id", resp.CreatePost.ID)
+}
+
+func TestDirectiveHasRole_SetUserRole(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		SetUserRole(gomock.Any(), "user-id", model.RoleModerator).
+		Return(&model.User{ID: "user-id", Username: "alice", Role: model.RoleModerator}, nil).
+		Times(1)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), []string{"admin-id"}).
+		Return(map[string]*model.User{"admin-id": {ID: "admin-id", Role: model.RoleAdmin}}, nil).
+		Times(1)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), []string{"demoted-id"}).
+		Return(map[string]*model.User{"demoted-id": {ID: "demoted-id", Role: model.RoleUser}}, nil).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		User_:   mockUser,
+	}
+	c := newTestClient(resolver)
+
+	const query = `mutation { setUserRole(userID: "user-id", role: MODERATOR) { id role } }`
+	var resp struct {
+		SetUserRole struct {
+			ID   string
+			Role string
+		}
+	}
+
+	err := c.Post(query, &resp, asUser("user-id", model.RoleUser))
+	require.ErrorContains(t, err, "access denied")
+
+	err = c.Post(query, &resp, asUser("moderator-id", model.RoleModerator))
+	require.ErrorContains(t, err, "access denied")
+
+	// роль в токене устарела: пользователя уже разжаловали
+	err = c.Post(query, &resp, asUser("demoted-id", model.RoleAdmin))
+	require.ErrorContains(t, err, "access denied")
+
+	err = c.Post(query, &resp, asUser("admin-id", model.RoleAdmin))
+	require.NoError(t, err)
+	require.Equal(t, "MODERATOR", resp.SetUserRole.Role)
+}
+
+func TestDirectiveIsOwner_Post(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	tPost := &model.Post{ID: "post-id", AuthorID: &owner, Title: "Title", Content: "Content", CreatedAt: tTime}
+	tAnonPost := &model.Post{ID: "anon-id", Title: "Title", Content: "Content", CreatedAt: tTime}
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "post-id").
+		Return(tPost, nil).
+		Times(3)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "anon-id").
+		Return(tAnonPost, nil).
+		Times(1)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "missing-id").
+		Return(nil, fmt.Errorf("storage.in-memory.GetPost: %w", apperr.ErrPostNotFound)).
+		Times(1)
+	mockPost.EXPECT().
+		UpdatePost(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tPost, nil).
+		Times(2)
+	mockPost.EXPECT().
+		SetCommentsAllowed(gomock.Any(), "post-id", false).
+		Return(tPost, nil).
+		Times(1)
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), []string{"moderator-id"}).
+		Return(map[string]*model.User{"moderator-id": {ID: "moderator-id", Role: model.RoleModerator}}, nil).
+		Times(1)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := &Resolver{
+		Log:      log,
+		Storage:  new(postgres.Storage),
+		Post_:    mockPost,
+		User_:    mockUser,
+		UqMutex:  uniquemutex.NewUqMutex(),
+		Notifier: notify.NewHub(log, 1),
+	}
+	c := newTestClient(resolver)
+
+	var resp map[string]any
+
+	// автор поста
+	err := c.Post(`mutation { updatePost(id: "post-id", title: "New") { id } }`, &resp, asUser(owner, model.RoleUser))
+	require.NoError(t, err)
+
+	// другой пользователь
+	err = c.Post(`mutation { updatePost(id: "post-id", title: "New") { id } }`, &resp, asUser("other-id", model.RoleUser))
+	require.ErrorContains(t, err, "access denied")
+	require.ErrorContains(t, err, `"code":"FORBIDDEN"`)
+
+	// модератор изменяет чужой пост без проверки автора
+	err = c.Post(`mutation { updatePost(id: "post-id", title: "New") { id } }`, &resp, asUser("moderator-id", model.RoleModerator))
+	require.NoError(t, err)
+
+	// ID поста передается в аргументе postID
+	err = c.Post(`mutation { setCommentsAllowed(postID: "post-id", allowed: false) { id } }`, &resp, asUser(owner, model.RoleUser))
+	require.NoError(t, err)
+
+	// пост без автора
+	err = c.Post(`mutation { deletePost(id: "anon-id") }`, &resp, asUser(owner, model.RoleUser))
+	require.ErrorContains(t, err, "access denied")
+
+	err = c.Post(`mutation { deletePost(id: "missing-id") }`, &resp, asUser(owner, model.RoleUser))
+	require.ErrorContains(t, err, `"message":"post not found"`)
+	require.ErrorContains(t, err, `"code":"NOT_FOUND"`)
+
+	err = c.Post(`mutation { deletePost(id: "post-id") }`, &resp)
+	require.ErrorContains(t, err, "authentication required")
+	require.ErrorContains(t, err, `"code":"UNAUTHENTICATED"`)
+}
+
+func TestDirectiveIsOwner_Comment(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	tComment := &model.Comment{ID: "comment-id", PostID: "post-id", AuthorID: &owner, Content: "Content", CreatedAt: tTime}
+
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+	mockComment.EXPECT().
+		GetComment(gomock.Any(), "comment-id").
+		Return(tComment, nil).
+		Times(2)
+	mockComment.EXPECT().
+		DeleteComment(gomock.Any(), "comment-id").
+		Return(tComment, nil).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage:  new(postgres.Storage),
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+	c := newTestClient(resolver)
+
+	var resp map[string]any
+
+	err := c.Post(`mutation { deleteComment(id: "comment-id") { id } }`, &resp, asUser("other-id", model.RoleUser))
+	require.ErrorContains(t, err, "access denied")
+
+	err = c.Post(`mutation { deleteComment(id: "comment-id") { id } }`, &resp, asUser(owner, model.RoleUser))
+	require.NoError(t, err)
+}
@@ -1,11 +1,15 @@
 package graph
 
 import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
 	"client-services/internal/graph/mocks"
 	"client-services/internal/graph/model"
 	uniquemutex "client-services/internal/graph/unique-mutex"
+	"client-services/internal/notify"
 	"client-services/internal/storage/postgres"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -40,7 +44,7 @@ func TestResolverCreatePost_Success(t *testing.T) {
 		tContent := fmt.Sprintf("Content-%d", i)
 		tCommAllowed := i%2 == 0
 
-		response, err := resolver.Mutation().CreatePost(context.Background(), tTitle, tContent, tCommAllowed)
+		response, err := resolver.Mutation().CreatePost(context.Background(), tTitle, tContent, tCommAllowed, false)
 
 		require.NoError(t, err)
 		require.Equal(t, "test-id", response.ID)
@@ -78,7 +82,7 @@ func TestResolverCreatePost_Failed(t *testing.T) {
 	}
 
 	for i := 0; i < 3; i++ {
-		post, err := resolver.Mutation().CreatePost(context.Background(), tests[i].tTitle, tests[i].tContent, true)
+		post, err := resolver.Mutation().CreatePost(context.Background(), tests[i].tTitle, tests[i].tContent, true, false)
 
 		require.ErrorContains(t, err, tests[i].tErr)
 		require.Nil(t, post)
@@ -130,7 +134,7 @@ func TestResolverGetPost_Success(t *testing.T) {
 	}
 
 	first := int32(5)
-	post, err := resolver.Query().GetPost(context.Background(), postID, &first, nil)
+	post, err := resolver.Query().GetPost(context.Background(), postID, &first, nil, nil)
 	require.NoError(t, err)
 	require.Equal(t, postID, post.ID)
 	require.Equal(t, "Title-0", post.Title)
@@ -157,14 +161,59 @@ func TestResolverGetPost_Failed(t *testing.T) {
 		UqMutex:  uniquemutex.NewUqMutex(),
 	}
 
-	post, err := resolver.Query().GetPost(context.Background(), "id-0", nil, nil)
-	require.ErrorContains(t, err, "parameter `first` is missing")
-	require.Nil(t, post)
-
 	first := int32(-5)
-	post, err = resolver.Query().GetPost(context.Background(), "id-0", &first, nil)
+	post, err := resolver.Query().GetPost(context.Background(), "id-0", &first, nil, nil)
 	require.ErrorContains(t, err, "`first` cannot be less than 0")
 	require.Nil(t, post)
+
+	comments, err := resolver.Post().Comments(context.Background(), &model.Post{ID: "id-0"}, nil, nil, nil)
+	require.ErrorContains(t, err, "parameter `first` is missing")
+	require.Nil(t, comments)
+
+	comments, err = resolver.Post().Comments(context.Background(), &model.Post{ID: "id-0"}, &first, nil, nil)
+	require.ErrorContains(t, err, "`first` cannot be less than 0")
+	require.Nil(t, comments)
+}
+
+func TestResolverPostComments(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	postID := "p-0"
+	comID := "c-0"
+	comm := []model.Comment{{ID: comID, PostID: postID, Content: "Content", CreatedAt: tTime}}
+
+	// без first комментарии не загружаются заранее
+	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(&model.Post{ID: postID}, nil)
+	mockComment.EXPECT().GetComments(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage:  new(postgres.Storage),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+
+	post, err := resolver.Query().GetPost(context.Background(), postID, nil, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, post.Comments)
+
+	// поле comments загружает свою страницу для любого поста, в том числе из списка
+	first := int32(1)
+	mockComment.EXPECT().GetComments(gomock.Any(), &first, nil, postID).Return(&comm, true, comID, nil)
+
+	comments, err := resolver.Post().Comments(context.Background(), post, &first, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, comments.Edges, 1)
+	require.Equal(t, comID, comments.Edges[0].Cursor)
+	require.Equal(t, comID, *comments.PageInfo.EndCursor)
+	require.True(t, comments.PageInfo.HasNextPage)
 }
 
 func TestResolverGetAllPosts_Success(t *testing.T) {
@@ -202,3 +251,422 @@ func TestResolverGetAllPosts_Success(t *testing.T) {
 	}
 
 }
+
+func TestResolverUpdatePost_Success(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+
+	postID := "p-0"
+	title := "New title"
+	mockPost.EXPECT().UpdatePost(gomock.Any(), postID, &title, nil).Return(
+		&model.Post{ID: postID,
+			Title:     title,
+			Content:   "Content-0",
+			CreatedAt: tTime,
+			EditedAt:  &tTime}, nil)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+	}
+
+	post, err := resolver.Mutation().UpdatePost(context.Background(), postID, &title, nil)
+	require.NoError(t, err)
+	require.Equal(t, title, post.Title)
+	require.Equal(t, "Content-0", post.Content)
+	require.Equal(t, &tTime, post.EditedAt)
+}
+
+func TestResolverUpdatePost_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().UpdatePost(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+	}
+
+	empty := " "
+	tests := []struct {
+		tTitle   *string
+		tContent *string
+		tErr     string
+	}{
+		{nil, nil, "nothing to update"},
+		{&empty, nil, "title cannot be empty"},
+		{nil, &empty, "content cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		post, err := resolver.Mutation().UpdatePost(context.Background(), "p-0", tt.tTitle, tt.tContent)
+		require.ErrorContains(t, err, tt.tErr)
+		require.Nil(t, post)
+	}
+}
+
+func TestResolverDeletePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().DeletePost(gomock.Any(), "p-0").Return(nil)
+	mockPost.EXPECT().DeletePost(gomock.Any(), "p-1").Return(errors.New("post not found"))
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+		UqMutex: uniquemutex.NewUqMutex(),
+	}
+
+	ok, err := resolver.Mutation().DeletePost(context.Background(), "p-0")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = resolver.Mutation().DeletePost(context.Background(), "p-1")
+	require.ErrorContains(t, err, "post not found")
+	require.False(t, ok)
+}
+
+func TestResolverSetCommentsAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+
+	postID := "p-0"
+	mockPost.EXPECT().GetPost(gomock.Any(), postID).Return(
+		&model.Post{ID: postID, Title: "Title-0", Content: "Content-0", CommentsAllowed: true}, nil)
+	mockPost.EXPECT().SetCommentsAllowed(gomock.Any(), postID, false).Return(
+		&model.Post{ID: postID, Title: "Title-0", Content: "Content-0", CommentsAllowed: false}, nil)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := &Resolver{
+		Log:      log,
+		Storage:  new(postgres.Storage),
+		Post_:    mockPost,
+		Notifier: notify.NewHub(log, 1),
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifies, err := resolver.Subscription().CommentsUpdated(ctx, postID)
+	require.NoError(t, err)
+
+	post, err := resolver.Mutation().SetCommentsAllowed(ctx, postID, false)
+	require.NoError(t, err)
+	require.False(t, post.CommentsAllowed)
+
+	n := <-notifies
+	require.Equal(t, model.NotifyEventCommentsAllowedChanged, n.Event)
+	require.Equal(t, postID, n.PostID)
+	require.NotNil(t, n.CommentsAllowed)
+	require.False(t, *n.CommentsAllowed)
+}
+
+func TestResolverPosts_Success(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+
+	rPosts := []model.Post{
+		{ID: "p-1", Title: "Title-1", Content: "Content-1", CreatedAt: tTime},
+		{ID: "p-0", Title: "Title-0", Content: "Content-0", CreatedAt: tTime},
+	}
+
+	first := int32(2)
+	after := "p-2"
+	mockPost.EXPECT().GetPosts(gomock.Any(), &first, &after, true).Return(&rPosts, true, "p-0", nil)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+	}
+
+	order := model.PostOrderCreatedAtDesc
+	posts, err := resolver.Query().Posts(context.Background(), &first, &after, &order)
+	require.NoError(t, err)
+	require.Len(t, posts.Edges, 2)
+	require.Equal(t, "p-1", posts.Edges[0].Cursor)
+	require.Equal(t, "Title-1", posts.Edges[0].Node.Title)
+	require.Equal(t, "p-0", *posts.PageInfo.EndCursor)
+	require.True(t, posts.PageInfo.HasNextPage)
+}
+
+func TestResolverPosts_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().GetPosts(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+	}
+
+	posts, err := resolver.Query().Posts(context.Background(), nil, nil, nil)
+	require.ErrorContains(t, err, "parameter `first` is missing")
+	require.Nil(t, posts)
+
+	first := int32(-1)
+	posts, err = resolver.Query().Posts(context.Background(), &first, nil, nil)
+	require.ErrorContains(t, err, "`first` cannot be less than 0")
+	require.Nil(t, posts)
+}
+
+func TestResolverPosts_CommentsBatched(t *testing.T) {
+	const tPosts = 10
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+
+	var rPosts []model.Post
+	for i := 0; i < tPosts; i++ {
+		rPosts = append(rPosts, model.Post{ID: fmt.Sprintf("p-%d", i), CreatedAt: tTime})
+	}
+	mockPost.EXPECT().GetPosts(gomock.Any(), gomock.Any(), nil, false).Return(&rPosts, false, "p-9", nil)
+
+	var batchedIDs []string
+	mockComment.EXPECT().GetComments(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockComment.EXPECT().
+		GetCommentsBatch(gomock.Any(), int32(2), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+			batchedIDs = append([]string(nil), postIDs...)
+			pages := make(map[string]*model.CommentsPage)
+			for _, id := range postIDs {
+				comID := "c-" + id
+				pages[id] = &model.CommentsPage{
+					Comments:  []model.Comment{{ID: comID, PostID: id, Content: "Content", CreatedAt: tTime}},
+					EndCursor: comID,
+				}
+			}
+			return pages, nil
+		}).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage:  new(postgres.Storage),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		UqMutex:  uniquemutex.NewUqMutex(),
+	}
+	batchExactly(t, tPosts)
+	c := newTestClient(resolver)
+
+	var resp struct {
+		Posts struct {
+			Edges []struct {
+				Node struct {
+					ID       string
+					Comments struct {
+						Edges []struct {
+							Cursor string
+						}
+					}
+				}
+			}
+		}
+	}
+	err := c.Post(`query { posts(first: 10) { edges { node { id comments(first: 2) { edges { cursor } } } } } }`, &resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Posts.Edges, tPosts)
+	for _, edge := range resp.Posts.Edges {
+		require.Len(t, edge.Node.Comments.Edges, 1)
+		require.Equal(t, "c-"+edge.Node.ID, edge.Node.Comments.Edges[0].Cursor)
+	}
+
+	var wantIDs []string
+	for _, post := range rPosts {
+		wantIDs = append(wantIDs, post.ID)
+	}
+	require.ElementsMatch(t, wantIDs, batchedIDs)
+}
+
+func TestResolverPrivatePost(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	tPosts := []model.Post{
+		{ID: "p-0", Title: "Public", CommentsAllowed: true, CreatedAt: tTime},
+		{ID: "p-1", AuthorID: &owner, Title: "Private", CommentsAllowed: true, Private: true, CreatedAt: tTime},
+	}
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().GetPost(gomock.Any(), "p-1").
+		DoAndReturn(func(ctx context.Context, id string) (*model.Post, error) {
+			post := tPosts[1]
+			return &post, nil
+		}).
+		AnyTimes()
+	mockPost.EXPECT().GetPosts(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+			posts := append([]model.Post(nil), tPosts...)
+			return &posts, false, "p-1", nil
+		}).
+		AnyTimes()
+	mockPost.EXPECT().GetAllPosts(gomock.Any()).
+		DoAndReturn(func(ctx context.Context) ([]model.Post, error) {
+			return append([]model.Post(nil), tPosts...), nil
+		}).
+		AnyTimes()
+
+	mockComment := mocks.NewMockCommentInterface(ctrl)
+	mockComment.EXPECT().GetComments(gomock.Any(), gomock.Any(), gomock.Any(), "p-1").
+		Return(&[]model.Comment{}, false, "", nil).
+		AnyTimes()
+	mockComment.EXPECT().SaveComment(gomock.Any(), gomock.Any()).
+		Return("c-0", tTime, nil).
+		AnyTimes()
+
+	// роль модератора проверяется по хранилищу: у разжалованного в токене осталась старая роль
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().GetUsers(gomock.Any(), []string{"moderator-id"}).
+		Return(map[string]*model.User{"moderator-id": {ID: "moderator-id", Role: model.RoleModerator}}, nil).
+		AnyTimes()
+	mockUser.EXPECT().GetUsers(gomock.Any(), []string{"demoted-id"}).
+		Return(map[string]*model.User{"demoted-id": {ID: "demoted-id", Role: model.RoleUser}}, nil).
+		AnyTimes()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := &Resolver{
+		Log:      log,
+		Storage:  new(postgres.Storage),
+		Post_:    mockPost,
+		Comment_: mockComment,
+		User_:    mockUser,
+		UqMutex:  uniquemutex.NewUqMutex(),
+		Notifier: notify.NewHub(log, 1),
+	}
+
+	ctx := context.Background()
+	first := int32(10)
+	after := ""
+	tests := []struct {
+		tCtx    context.Context
+		tAccess bool
+	}{
+		{ctx, false},
+		{auth.WithUser(ctx, &auth.User{ID: "other-id", Role: model.RoleUser}), false},
+		{auth.WithUser(ctx, &auth.User{ID: owner, Role: model.RoleUser}), true},
+		{auth.WithUser(ctx, &auth.User{ID: "moderator-id", Role: model.RoleModerator}), true},
+		{auth.WithUser(ctx, &auth.User{ID: "demoted-id", Role: model.RoleModerator}), false},
+	}
+
+	for _, tt := range tests {
+		post, err := resolver.Query().GetPost(tt.tCtx, "p-1", nil, nil, nil)
+		if tt.tAccess {
+			require.NoError(t, err)
+			require.Equal(t, "p-1", post.ID)
+		} else {
+			require.ErrorIs(t, err, apperr.ErrAccessDenied)
+			require.Nil(t, post)
+		}
+
+		posts, err := resolver.Query().Posts(tt.tCtx, &first, &after, nil)
+		require.NoError(t, err)
+		all, err := resolver.Query().GetAllPosts(tt.tCtx)
+		require.NoError(t, err)
+		if tt.tAccess {
+			require.Len(t, posts.Edges, 2)
+			require.Len(t, all, 2)
+			require.Equal(t, "p-1", *posts.PageInfo.EndCursor)
+		} else {
+			require.Len(t, posts.Edges, 1)
+			require.Equal(t, "p-0", posts.Edges[0].Node.ID)
+			require.Len(t, all, 1)
+			require.Equal(t, "p-0", all[0].ID)
+			// курсор не раскрывает ID пропущенного закрытого поста
+			require.Equal(t, "p-0", *posts.PageInfo.EndCursor)
+		}
+
+		comments, err := resolver.Post().Comments(tt.tCtx, &tPosts[1], &first, nil, nil)
+		if tt.tAccess {
+			require.NoError(t, err)
+			require.NotNil(t, comments)
+		} else {
+			require.ErrorIs(t, err, apperr.ErrAccessDenied)
+		}
+
+		comment, err := resolver.Mutation().CreateComment(tt.tCtx, nil, "p-1", "Content")
+		if tt.tAccess {
+			require.NoError(t, err)
+			require.Equal(t, "c-0", comment.ID)
+		} else {
+			require.ErrorIs(t, err, apperr.ErrAccessDenied)
+		}
+	}
+}
+
+func TestResolverPosts_SkipPrivate(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	tPosts := []model.Post{
+		{ID: "p-0", Title: "Public", CreatedAt: tTime},
+		{ID: "p-1", AuthorID: &owner, Title: "Private", Private: true, CreatedAt: tTime},
+		{ID: "p-2", Title: "Public", CreatedAt: tTime},
+		{ID: "p-3", AuthorID: &owner, Title: "Private", Private: true, CreatedAt: tTime},
+	}
+
+	// закрытый пост занимает место на странице хранилища, поэтому страница дочитывается
+	first, second := int32(2), int32(1)
+	cursor, lastCursor := "p-1", "p-2"
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	gomock.InOrder(
+		mockPost.EXPECT().GetPosts(gomock.Any(), &first, nil, false).
+			Return(&[]model.Post{tPosts[0], tPosts[1]}, true, "p-1", nil),
+		mockPost.EXPECT().GetPosts(gomock.Any(), &second, &cursor, false).
+			Return(&[]model.Post{tPosts[2]}, true, "p-2", nil),
+		mockPost.EXPECT().GetPosts(gomock.Any(), &first, &lastCursor, false).
+			Return(&[]model.Post{tPosts[3]}, false, "p-3", nil),
+	)
+
+	resolver := &Resolver{
+		Log:   slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Post_: mockPost,
+	}
+	ctx := auth.WithUser(context.Background(), &auth.User{ID: "other-id", Role: model.RoleUser})
+
+	posts, err := resolver.Query().Posts(ctx, &first, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, posts.Edges, 2)
+	require.Equal(t, "p-0", posts.Edges[0].Node.ID)
+	require.Equal(t, "p-2", posts.Edges[1].Node.ID)
+	require.True(t, posts.PageInfo.HasNextPage)
+	require.Equal(t, "p-2", *posts.PageInfo.EndCursor)
+
+	// на последней странице только закрытый пост: курсор пустой, а не его ID
+	posts, err = resolver.Query().Posts(ctx, &first, posts.PageInfo.EndCursor, nil)
+	require.NoError(t, err)
+	require.Empty(t, posts.Edges)
+	require.False(t, posts.PageInfo.HasNextPage)
+	require.Empty(t, *posts.PageInfo.EndCursor)
+}
@@ -0,0 +1,293 @@
+package graph
+
+import (
+	"client-services/internal/auth"
+	"client-services/internal/config"
+	"client-services/internal/graph/mocks"
+	"client-services/internal/graph/model"
+	"client-services/internal/notify"
+	authmw "client-services/internal/server/middlewares/auth"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverCommentsUpdated_Broadcast(t *testing.T) {
+	const tSubscribers = 3
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string) (*model.Post, error) {
+			return &model.Post{ID: id}, nil
+		}).
+		Times(tSubscribers + 1)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := &Resolver{
+		Log:      log,
+		Post_:    mockPost,
+		Notifier: notify.NewHub(log, 4),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var channels []<-chan *model.CommentNotify
+	for i := 0; i < tSubscribers; i++ {
+		ch, err := resolver.Subscription().CommentsUpdated(ctx, "post-0")
+		require.NoError(t, err)
+		channels = append(channels, ch)
+	}
+	other, err := resolver.Subscription().CommentsUpdated(ctx, "post-1")
+	require.NoError(t, err)
+
+	tNotify := &model.CommentNotify{PostID: "post-0", ID: "c-0", Content: "Content"}
+	require.NoError(t, resolver.Notifier.Publish(ctx, tNotify))
+
+	for _, ch := range channels {
+		select {
+		case n := <-ch:
+			require.Equal(t, tNotify, n)
+		case <-time.After(time.Second):
+			t.Fatal("notify was not delivered")
+		}
+	}
+
+	select {
+	case n := <-other:
+		t.Fatalf("unexpected notify for another post: %v", n)
+	default:
+	}
+}
+
+func TestResolverCommentsUpdated_SlowConsumer(t *testing.T) {
+	const tBuffer = 2
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "post-0").
+		Return(&model.Post{ID: "post-0"}, nil).
+		Times(1)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	hub := notify.NewHub(log, tBuffer)
+	resolver := &Resolver{
+		Log:      log,
+		Post_:    mockPost,
+		Notifier: hub,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	slow, err := resolver.Subscription().CommentsUpdated(ctx, "post-0")
+	require.NoError(t, err)
+
+	for i := 0; i < tBuffer+3; i++ {
+		require.NoError(t, hub.Publish(ctx, &model.CommentNotify{PostID: "post-0"}))
+	}
+	require.Equal(t, uint64(3), hub.Dropped())
+
+	cancel()
+	received := 0
+	for range slow {
+		received++
+	}
+	require.Equal(t, tBuffer, received)
+	require.Equal(t, 0, hub.Subscribers())
+}
+
+func TestResolverCommentsUpdated_PrivatePost(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "post-0").
+		Return(&model.Post{ID: "post-0", AuthorID: &owner, Private: true}, nil).
+		Times(5)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "missing").
+		Return(nil, errors.New("post not found")).
+		Times(1)
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), []string{"moderator-id"}).
+		Return(map[string]*model.User{"moderator-id": {ID: "moderator-id", Role: model.RoleModerator}}, nil).
+		Times(1)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), []string{"demoted-id"}).
+		Return(map[string]*model.User{"demoted-id": {ID: "demoted-id", Role: model.RoleUser}}, nil).
+		Times(1)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := &Resolver{
+		Log:      log,
+		Post_:    mockPost,
+		User_:    mockUser,
+		Notifier: notify.NewHub(log, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		tCtx context.Context
+		tErr string
+	}{
+		{ctx, "access denied"},
+		{auth.WithUser(ctx, &auth.User{ID: "other-id", Role: model.RoleUser}), "access denied"},
+		{auth.WithUser(ctx, &auth.User{ID: owner, Role: model.RoleUser}), ""},
+		{auth.WithUser(ctx, &auth.User{ID: "moderator-id", Role: model.RoleModerator}), ""},
+		{auth.WithUser(ctx, &auth.User{ID: "demoted-id", Role: model.RoleModerator}), "access denied"},
+	}
+
+	for _, tt := range tests {
+		ch, err := resolver.Subscription().CommentsUpdated(tt.tCtx, "post-0")
+		if tt.tErr != "" {
+			require.ErrorContains(t, err, tt.tErr)
+			require.Nil(t, ch)
+			continue
+		}
+		require.NoError(t, err)
+		require.NotNil(t, ch)
+	}
+
+	ch, err := resolver.Subscription().CommentsUpdated(ctx, "missing")
+	require.ErrorContains(t, err, "post not found")
+	require.Nil(t, ch)
+}
+
+func TestWebsocketInit_Token(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := "owner-id"
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "post-0").
+		Return(&model.Post{ID: "post-0", AuthorID: &owner, Private: true}, nil).
+		AnyTimes()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	manager := newTestAuth(t)
+	resolver := &Resolver{
+		Log:      log,
+		Post_:    mockPost,
+		Notifier: notify.NewHub(log, 1),
+		Auth:     manager,
+	}
+
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver, Directives: NewDirectives(resolver)}))
+	srv.AddTransport(transport.Websocket{
+		InitFunc: authmw.WebsocketInit(log, manager),
+	})
+	srv.SetErrorPresenter(NewErrorPresenter(log))
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(WithLoaders(ctx, resolver))
+	})
+	c := client.New(srv)
+
+	const query = `subscription { commentsUpdated(postID: "post-0") { id } }`
+	var resp struct {
+		CommentsUpdated struct {
+			ID string
+		}
+	}
+
+	// недействительный токен - соединение отклоняется
+	sub := c.WebsocketWithPayload(query, map[string]any{"Authorization": "Bearer invalid"})
+	require.Error(t, sub.Next(&resp))
+	sub.Close()
+
+	// анонимная подписка на закрытый пост
+	sub = c.Websocket(query)
+	require.ErrorContains(t, sub.Next(&resp), "access denied")
+	sub.Close()
+
+	// автор получает уведомления до истечения срока действия токена
+	token, _, err := manager.Issue(owner, "alice", model.RoleUser)
+	require.NoError(t, err)
+
+	sub = c.WebsocketWithPayload(query, map[string]any{"Authorization": "Bearer " + token})
+	defer sub.Close()
+
+	go func() {
+		for resolver.Notifier.(*notify.Hub).Subscribers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		_ = resolver.Notifier.Publish(context.Background(), &model.CommentNotify{PostID: "post-0", ID: "c-0"})
+	}()
+
+	require.NoError(t, sub.Next(&resp))
+	require.Equal(t, "c-0", resp.CommentsUpdated.ID)
+}
+
+func TestWebsocketInit_TokenExpired(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPost(gomock.Any(), "post-0").
+		Return(&model.Post{ID: "post-0"}, nil).
+		Times(1)
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	manager, err := auth.NewManager(&config.Auth{JWTSecret: "test-secret", TokenTTL: time.Second})
+	require.NoError(t, err)
+	resolver := &Resolver{
+		Log:      log,
+		Post_:    mockPost,
+		Notifier: notify.NewHub(log, 1),
+		Auth:     manager,
+	}
+
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver, Directives: NewDirectives(resolver)}))
+	srv.AddTransport(transport.Websocket{
+		InitFunc: authmw.WebsocketInit(log, manager),
+	})
+	srv.SetErrorPresenter(NewErrorPresenter(log))
+	c := client.New(srv)
+
+	token, _, err := manager.Issue("user-id", "alice", model.RoleUser)
+	require.NoError(t, err)
+
+	sub := c.WebsocketWithPayload(`subscription { commentsUpdated(postID: "post-0") { id } }`,
+		map[string]any{"Authorization": token})
+	defer sub.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		var resp map[string]any
+		done <- sub.Next(&resp)
+	}()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection was not closed after token expiration")
+	}
+}
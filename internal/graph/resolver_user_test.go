@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
+	"client-services/internal/config"
+	"client-services/internal/graph/mocks"
+	"client-services/internal/graph/model"
+	"client-services/internal/storage/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuth(t *testing.T) *auth.Manager {
+	manager, err := auth.NewManager(&config.Auth{JWTSecret: "test-secret", TokenTTL: time.Hour})
+	require.NoError(t, err)
+	return manager
+}
+
+func TestResolverRegister_Success(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		SaveUser(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, u *model.User, hash string) (string, time.Time, error) {
+			require.Equal(t, "alice", u.Username)
+			require.Equal(t, model.RoleUser, u.Role)
+			require.True(t, auth.CheckPassword(hash, "password123"))
+			return "user-id", tTime, nil
+		}).
+		Times(1)
+
+	manager := newTestAuth(t)
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		User_:   mockUser,
+		Auth:    manager,
+	}
+
+	response, err := resolver.Mutation().Register(context.Background(), " alice ", "password123")
+
+	require.NoError(t, err)
+	require.Equal(t, "user-id", response.User.ID)
+	require.Equal(t, "alice", response.User.Username)
+	require.Equal(t, tTime, response.User.CreatedAt)
+
+	user, err := manager.Parse(response.Token)
+	require.NoError(t, err)
+	require.Equal(t, "user-id", user.ID)
+	require.Equal(t, "alice", user.Username)
+	require.Equal(t, model.RoleUser, user.Role)
+}
+
+func TestResolverRegister_NoSelfAdmin(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		SaveUser(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("admin-id", time.Now(), nil).
+		Times(1)
+
+	manager := newTestAuth(t)
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		User_:   mockUser,
+		Auth:    manager,
+	}
+
+	// имя не дает прав: администратора назначают только командой promote
+	response, err := resolver.Mutation().Register(context.Background(), "admin", "password123")
+	require.NoError(t, err)
+	require.Equal(t, model.RoleUser, response.User.Role)
+
+	user, err := manager.Parse(response.Token)
+	require.NoError(t, err)
+	require.Equal(t, model.RoleUser, user.Role)
+}
+
+func TestResolverRegister_Failed(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		SaveUser(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		User_:   mockUser,
+		Auth:    newTestAuth(t),
+	}
+
+	tests := []struct {
+		tUsername string
+		tPassword string
+		tErr      string
+	}{
+		{"al", "password123", "username must have from 3 to 32 chars"},
+		{"al ice", "password123", "username cannot contain spaces"},
+		{"alice", "short", "password must have 8 chars or more"},
+	}
+
+	for _, tt := range tests {
+		response, err := resolver.Mutation().Register(context.Background(), tt.tUsername, tt.tPassword)
+
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Contains(t, err.Error(), tt.tErr)
+	}
+}
+
+func TestResolverLogin(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hash, err := auth.HashPassword("password123")
+	require.NoError(t, err)
+	tUser := &model.User{ID: "user-id", Username: "alice", CreatedAt: tTime}
+
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		GetUserByName(gomock.Any(), "alice").
+		Return(tUser, hash, nil).
+		Times(2)
+	mockUser.EXPECT().
+		GetUserByName(gomock.Any(), "bob").
+		Return(nil, "", fmt.Errorf("storage.in-memory.GetUserByName: %w", apperr.ErrUserNotFound)).
+		Times(1)
+	mockUser.EXPECT().
+		GetUserByName(gomock.Any(), "carol").
+		Return(nil, "", errors.New("connection refused")).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		User_:   mockUser,
+		Auth:    newTestAuth(t),
+	}
+
+	response, err := resolver.Mutation().Login(context.Background(), "alice", "password123")
+	require.NoError(t, err)
+	require.Equal(t, tUser, response.User)
+	require.NotEmpty(t, response.Token)
+
+	response, err = resolver.Mutation().Login(context.Background(), "alice", "wrong-password")
+	require.Error(t, err)
+	require.Nil(t, response)
+	require.Contains(t, err.Error(), "invalid username or password")
+
+	response, err = resolver.Mutation().Login(context.Background(), "bob", "password123")
+	require.Error(t, err)
+	require.Nil(t, response)
+	require.Contains(t, err.Error(), "invalid username or password")
+
+	response, err = resolver.Mutation().Login(context.Background(), "carol", "password123")
+	require.Error(t, err)
+	require.Nil(t, response)
+	require.Contains(t, err.Error(), "failed to get user")
+}
+
+func TestResolverAuthor_Batched(t *testing.T) {
+	var tTime = time.Date(2025, 9, 30, 20, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authorA, authorB := "user-a", "user-b"
+	tPosts := []model.Post{
+		{ID: "p-0", AuthorID: &authorA, Title: "Title", Content: "Content", CreatedAt: tTime},
+		{ID: "p-1", AuthorID: &authorB, Title: "Title", Content: "Content", CreatedAt: tTime},
+		{ID: "p-2", AuthorID: &authorA, Title: "Title", Content: "Content", CreatedAt: tTime},
+		{ID: "p-3", Title: "Title", Content: "Content", CreatedAt: tTime},
+	}
+
+	mockPost := mocks.NewMockPostInterface(ctrl)
+	mockPost.EXPECT().
+		GetPosts(gomock.Any(), gomock.Any(), gomock.Any(), false).
+		Return(&tPosts, false, "p-3", nil).
+		Times(1)
+
+	var batchedIDs []string
+	mockUser := mocks.NewMockUserInterface(ctrl)
+	mockUser.EXPECT().
+		GetUsers(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ids []string) (map[string]*model.User, error) {
+			batchedIDs = append([]string(nil), ids...)
+			return map[string]*model.User{
+				authorA: {ID: authorA, Username: "alice"},
+				authorB: {ID: authorB, Username: "bob"},
+			}, nil
+		}).
+		Times(1)
+
+	resolver := &Resolver{
+		Log:     slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Storage: new(postgres.Storage),
+		Post_:   mockPost,
+		User_:   mockUser,
+	}
+	batchExactly(t, 2)
+	c := newTestClient(resolver)
+
+	var resp struct {
+		Posts struct {
+			Edges []struct {
+				Node struct {
+					ID     string
+					Author *struct {
+						Username string
+					}
+				}
+			}
+		}
+	}
+	err := c.Post(`query { posts(first: 4) { edges { node { id author { username } } } } }`, &resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Posts.Edges, 4)
+	require.Equal(t, "alice", resp.Posts.Edges[0].Node.Author.Username)
+	require.Equal(t, "bob", resp.Posts.Edges[1].Node.Author.Username)
+	require.Equal(t, "alice", resp.Posts.Edges[2].Node.Author.Username)
+	require.Nil(t, resp.Posts.Edges[3].Node.Author)
+	require.ElementsMatch(t, []string{authorA, authorB}, batchedIDs)
+}
@@ -5,30 +5,157 @@ package graph
 // Code generated by github.com/99designs/gqlgen version v0.17.81
 
 import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
 	"client-services/internal/graph/model"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 )
 
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*model.User, error) {
+	const op = "graph.schema.resolvers.CommentAuthor"
+
+	if obj.AuthorID == nil {
+		return nil, nil
+	}
+
+	user, err := r.loadUser(ctx, *obj.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get author: %w", op, err)
+	}
+	return user, nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first *int32, after *string) (*model.CommentConnection, error) {
+	const op = "graph.schema.resolvers.Replies"
+
+	// предзагруженные ответы используются, только если они полные; иначе страница читается из хранилища
+	if obj.Replies != nil && (!obj.Replies.PageInfo.HasNextPage || first == nil && after == nil) {
+		if first == nil && after == nil {
+			return obj.Replies, nil
+		}
+		if first != nil && *first < 0 {
+			return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`first` cannot be less than 0"))
+		}
+		replies, err := paginateConnection(obj.Replies, first, after)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return replies, nil
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`first` cannot be less than 0"))
+	}
+
+	replies, hasNextPage, newCursor, err := r.Comment_.GetReplies(ctx, first, after, obj.PostID, &obj.ID)
+	if err != nil {
+		r.Log.Error("failed to get replies",
+			slog.String("op", op),
+			slog.String("commentID", obj.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to get replies: %w", op, err)
+	}
+
+	return newCommentConnection(*replies, hasNextPage, newCursor), nil
+}
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, username string, password string) (*model.AuthPayload, error) {
+	const op = "graph.schema.resolvers.Register"
+
+	username = strings.TrimSpace(username)
+	if err := validateCredentials(username, password); err != nil {
+		return nil, err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to hash password: %w", op, err)
+	}
+
+	user := &model.User{Username: username, Role: model.RoleUser}
+	id, time, err := r.User_.SaveUser(ctx, user, hash)
+	if err != nil {
+		r.Log.Info("failed to save user",
+			slog.String("op", op),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to save user: %w", op, err)
+	}
+
+	user.ID = id
+	user.CreatedAt = time
+
+	payload, err := r.issueToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Log.Info("user successfully registered",
+		slog.String("userID", id),
+	)
+	return payload, nil
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, username string, password string) (*model.AuthPayload, error) {
+	const op = "graph.schema.resolvers.Login"
+
+	user, hash, err := r.User_.GetUserByName(ctx, strings.TrimSpace(username))
+	if err != nil && !errors.Is(err, apperr.ErrUserNotFound) {
+		r.Log.Error("failed to get user",
+			slog.String("op", op),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to get user: %w", op, err)
+	}
+	if err != nil || !auth.CheckPassword(hash, password) {
+		r.Log.Info("failed login attempt",
+			slog.String("op", op),
+			slog.String("username", username),
+		)
+		return nil, apperr.Unauthenticated("invalid username or password")
+	}
+
+	payload, err := r.issueToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Log.Info("user successfully logged in",
+		slog.String("userID", user.ID),
+	)
+	return payload, nil
+}
+
 // CreatePost is the resolver for the createPost field.
-func (r *mutationResolver) CreatePost(ctx context.Context, title string, content string, commentsAllowed bool) (*model.Post, error) {
+func (r *mutationResolver) CreatePost(ctx context.Context, title string, content string, commentsAllowed bool, private bool) (*model.Post, error) {
 	const op = "graph.schema.resolvers.CreatePost"
 
 	if strings.TrimSpace(title) == "" {
 		r.Log.Debug("user tries create post with empty title")
-		return nil, fmt.Errorf("title cannot be empty")
+		return nil, apperr.Validation("title cannot be empty")
 	}
 	if strings.TrimSpace(content) == "" {
 		r.Log.Debug("user tries create post with empty content")
-		return nil, fmt.Errorf("content cannot be empty")
+		return nil, apperr.Validation("content cannot be empty")
 	}
 
 	post := &model.Post{
+		AuthorID:        authorIDFrom(ctx),
 		Title:           title,
 		Content:         content,
 		CommentsAllowed: commentsAllowed,
+		Private:         private,
 	}
 
 	id, time, err := r.Post_.SavePost(ctx, post)
@@ -55,16 +182,20 @@ func (r *mutationResolver) CreateComment(ctx context.Context, parentID *string,
 	if len(content) > 2000 {
 		r.Log.Error("text must have 2000 chars or less",
 			slog.String("op", op))
-		return nil, fmt.Errorf("%s: text must have 2000 chars or less", op)
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("text must have 2000 chars or less"))
 	}
 
 	if strings.TrimSpace(content) == "" {
-		return nil, fmt.Errorf("content cannot be empty")
+		return nil, apperr.Validation("content cannot be empty")
 	}
 
+	m := r.UqMutex.GetMutex(postID)
+	m.RLock()
+	defer m.RUnlock()
+
 	post, err := r.Post_.GetPost(ctx, postID)
 	if err != nil {
-		if strings.Contains(err.Error(), "post not found") {
+		if errors.Is(err, apperr.ErrPostNotFound) {
 			r.Log.Info("user trying to create comment to not existing post",
 				slog.String("op", op),
 				slog.String("error", err.Error()))
@@ -76,76 +207,280 @@ func (r *mutationResolver) CreateComment(ctx context.Context, parentID *string,
 		)
 		return nil, fmt.Errorf("%s: failed to get post for comment: %w", op, err)
 	}
+	if post.Private && !r.canAccessPrivate(ctx, post) {
+		r.Log.Info("user trying to create comment to private post",
+			slog.String("op", op),
+			slog.String("postID", postID),
+		)
+		return nil, apperr.ErrAccessDenied
+	}
 
-	m := r.UqMutex.GetMutex(postID)
-	m.RLock()
-	defer m.RUnlock()
 	if !post.CommentsAllowed {
 		r.Log.Info("user trying to create comment to post that not allowed comments",
 			slog.String("op", op))
-		return nil, fmt.Errorf("%s: this post not allow comments", op)
+		return nil, fmt.Errorf("%s: %w", op, apperr.Forbidden("this post not allow comments"))
 	}
 
-	insertParent := false
-	if parentID != nil {
-		err = r.Comment_.IsCommentExist(ctx, *parentID, postID)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				r.Log.Info("parrent comment not found",
-					slog.String("op", op),
-					slog.String("commentID", *parentID),
-				)
-				return nil, fmt.Errorf("%s: parrent comment not found: %w", op, err)
-			}
-			if strings.Contains(err.Error(), "this comment from another post") {
-				r.Log.Info("parrent comment from another post",
-					slog.String("op", op),
-					slog.String("commentID", *parentID),
-				)
-				return nil, fmt.Errorf("%s: parrent comment from another post: %w", op, err)
-			}
+	// существование родительского комментария в том же посте проверяет хранилище при сохранении
+	comment := &model.Comment{
+		PostID:   postID,
+		ParentID: parentID,
+		AuthorID: authorIDFrom(ctx),
+		Content:  content,
+	}
 
-			r.Log.Info("failed to find parent comment",
+	id, time, err := r.Comment_.SaveComment(ctx, comment)
+	if err != nil {
+		if errors.Is(err, apperr.ErrParentNotFound) {
+			r.Log.Info("parrent comment not found",
 				slog.String("op", op),
 				slog.String("commentID", *parentID),
-				slog.String("error", err.Error()),
 			)
-			return nil, fmt.Errorf("%s: failed to find parent comment: %w", op, err)
+			return nil, fmt.Errorf("%s: parrent comment not found: %w", op, err)
 		}
-
-		insertParent = true
+		r.Log.Error("failed to save comment",
+			slog.String("op", op),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to save comment: %w", op, err)
 	}
 
-	comment := &model.Comment{
+	comment.ID = id
+	comment.CreatedAt = time
+
+	notify := &model.CommentNotify{
 		PostID:  postID,
-		Content: content,
+		ID:      comment.ID,
+		Content: comment.Content,
+		Event:   model.NotifyEventCommentAdded,
 	}
-	if insertParent {
-		comment.ParentID = parentID
+	if err := r.Notifier.Publish(ctx, notify); err != nil {
+		r.Log.Error("failed to publish notify",
+			slog.String("op", op),
+			slog.String("commentID", id),
+			slog.String("error", err.Error()),
+		)
 	}
+	r.Log.Info("comment successfully saved",
+		slog.String("commentID", id),
+		slog.String("postID", postID),
+	)
+	return comment, nil
+}
 
-	id, time, err := r.Comment_.SaveComment(ctx, comment)
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	const op = "graph.schema.resolvers.UpdatePost"
+
+	if title == nil && content == nil {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("nothing to update"))
+	}
+	if title != nil && strings.TrimSpace(*title) == "" {
+		r.Log.Debug("user tries update post with empty title")
+		return nil, apperr.Validation("title cannot be empty")
+	}
+	if content != nil && strings.TrimSpace(*content) == "" {
+		r.Log.Debug("user tries update post with empty content")
+		return nil, apperr.Validation("content cannot be empty")
+	}
+
+	post, err := r.Post_.UpdatePost(ctx, id, title, content)
 	if err != nil {
-		r.Log.Error("failed to save comment",
+		r.Log.Error("failed to update post",
 			slog.String("op", op),
+			slog.String("postID", id),
 			slog.String("error", err.Error()),
 		)
-		return nil, fmt.Errorf("%s: failed to save comment: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to update post: %w", op, err)
 	}
 
-	comment.ID = id
-	comment.CreatedAt = time
+	r.Log.Info("post successfully updated",
+		slog.String("postID", id),
+	)
+	return post, nil
+}
 
-	go func() {
-		r.CommentAdded <- &model.CommentNotify{PostID: postID, ID: comment.ID, Content: comment.Content}
-	}()
-	r.Log.Info("comment successfully saved",
+// DeletePost is the resolver for the deletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, id string) (bool, error) {
+	const op = "graph.schema.resolvers.DeletePost"
+
+	m := r.UqMutex.GetMutex(id)
+	m.Lock()
+	defer m.Unlock()
+
+	if err := r.Post_.DeletePost(ctx, id); err != nil {
+		r.Log.Error("failed to delete post",
+			slog.String("op", op),
+			slog.String("postID", id),
+			slog.String("error", err.Error()),
+		)
+		return false, fmt.Errorf("%s: failed to delete post: %w", op, err)
+	}
+
+	r.Log.Info("post successfully deleted",
+		slog.String("postID", id),
+	)
+	return true, nil
+}
+
+// UpdateComment is the resolver for the updateComment field.
+func (r *mutationResolver) UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error) {
+	const op = "graph.schema.resolvers.UpdateComment"
+
+	if len(content) > 2000 {
+		r.Log.Error("text must have 2000 chars or less",
+			slog.String("op", op))
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("text must have 2000 chars or less"))
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, apperr.Validation("content cannot be empty")
+	}
+
+	comment, err := r.Comment_.UpdateComment(ctx, id, content)
+	if err != nil {
+		r.Log.Error("failed to update comment",
+			slog.String("op", op),
+			slog.String("commentID", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to update comment: %w", op, err)
+	}
+
+	r.Log.Info("comment successfully updated",
+		slog.String("commentID", id),
+	)
+	return comment, nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "graph.schema.resolvers.DeleteComment"
+
+	comment, err := r.Comment_.DeleteComment(ctx, id)
+	if err != nil {
+		r.Log.Error("failed to delete comment",
+			slog.String("op", op),
+			slog.String("commentID", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to delete comment: %w", op, err)
+	}
+
+	r.Log.Info("comment successfully deleted",
 		slog.String("commentID", id),
-		slog.String("postID", postID),
 	)
 	return comment, nil
 }
 
+// SetCommentsAllowed is the resolver for the setCommentsAllowed field.
+func (r *mutationResolver) SetCommentsAllowed(ctx context.Context, postID string, allowed bool) (*model.Post, error) {
+	const op = "graph.schema.resolvers.SetCommentsAllowed"
+
+	// запись флага под тем же мьютексом, под которым CreateComment его читает
+	m := r.UqMutex.GetMutex(postID)
+	m.Lock()
+	defer m.Unlock()
+
+	post, err := r.Post_.SetCommentsAllowed(ctx, postID, allowed)
+	if err != nil {
+		r.Log.Error("failed to set comments allowed",
+			slog.String("op", op),
+			slog.String("postID", postID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to set comments allowed: %w", op, err)
+	}
+
+	notify := &model.CommentNotify{
+		PostID:          postID,
+		ID:              postID,
+		Event:           model.NotifyEventCommentsAllowedChanged,
+		CommentsAllowed: &allowed,
+	}
+	if err := r.Notifier.Publish(ctx, notify); err != nil {
+		r.Log.Error("failed to publish notify",
+			slog.String("op", op),
+			slog.String("postID", postID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	r.Log.Info("comments allowed changed",
+		slog.String("postID", postID),
+		slog.Bool("allowed", allowed),
+	)
+	return post, nil
+}
+
+// SetUserRole is the resolver for the setUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, userID string, role model.Role) (*model.User, error) {
+	const op = "graph.schema.resolvers.SetUserRole"
+
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("unknown role %q", role))
+	}
+
+	user, err := r.User_.SetUserRole(ctx, userID, role)
+	if err != nil {
+		r.Log.Error("failed to set user role",
+			slog.String("op", op),
+			slog.String("userID", userID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to set user role: %w", op, err)
+	}
+
+	r.Log.Info("user role successfully changed",
+		slog.String("userID", userID),
+		slog.String("role", role.String()),
+	)
+	return user, nil
+}
+
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *model.Post) (*model.User, error) {
+	const op = "graph.schema.resolvers.PostAuthor"
+
+	if obj.AuthorID == nil {
+		return nil, nil
+	}
+
+	user, err := r.loadUser(ctx, *obj.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get author: %w", op, err)
+	}
+	return user, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int32, after *string, maxDepth *int32) (*model.CommentConnection, error) {
+	const op = "graph.schema.resolvers.Comments"
+
+	if obj.Private && !r.canAccessPrivate(ctx, obj) {
+		return nil, apperr.ErrAccessDenied
+	}
+
+	if obj.Comments != nil && first == nil && after == nil && maxDepth == nil {
+		return obj.Comments, nil
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`first` cannot be less than 0"))
+	}
+	if maxDepth != nil && *maxDepth < 0 {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`maxDepth` cannot be less than 0"))
+	}
+
+	comments, err := r.loadComments(ctx, obj.ID, first, after, maxDepth, isFieldRequested(ctx, "totalCount"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, nil
+}
+
 // GetAllPosts is the resolver for the getAllPosts field.
 func (r *queryResolver) GetAllPosts(ctx context.Context) ([]*model.Post, error) {
 	const op = "graph.schema.resolvers.GetAllPosts"
@@ -158,85 +493,130 @@ func (r *queryResolver) GetAllPosts(ctx context.Context) ([]*model.Post, error)
 
 	var result []*model.Post
 	for i := range posts {
+		if posts[i].Private && !r.canAccessPrivate(ctx, &posts[i]) {
+			continue
+		}
 		result = append(result, &posts[i])
 	}
 	r.Log.Info("posts was get successfully")
 	return result, nil
 }
 
-// GetPost is the resolver for the getPost field.
-func (r *queryResolver) GetPost(ctx context.Context, id string, first *int32, after *string) (*model.Post, error) {
-	const op = "graph.schema.resolvers.GetPost"
+// Posts is the resolver for the posts field.
+func (r *queryResolver) Posts(ctx context.Context, first *int32, after *string, orderBy *model.PostOrder) (*model.PostConnection, error) {
+	const op = "graph.schema.resolvers.Posts"
 
 	if first == nil {
-		return nil, fmt.Errorf("%s: parameter `first` is missing", op)
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
 	} else if *first < 0 {
-		return nil, fmt.Errorf("%s: `first` cannot be less than 0", op)
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`first` cannot be less than 0"))
 	}
 
-	post, err := r.Post_.GetPost(ctx, id)
-	if err != nil {
-		r.Log.Error("failed to get post",
-			slog.String("op", op),
-			slog.String("error", err.Error()),
-		)
-		return nil, fmt.Errorf("%s: failed to get post: %w", op, err)
-	}
+	desc := orderBy != nil && *orderBy == model.PostOrderCreatedAtDesc
 
-	m := r.UqMutex.GetMutex(id)
-	m.RLock()
-	defer m.RUnlock()
-	if after != nil {
-		err = r.Comment_.IsCommentExist(ctx, *after, id)
+	// закрытые посты других пользователей пропускаются, а страница дочитывается из хранилища до first постов.
+	// Курсор берется из последнего выданного поста, чтобы не раскрывать ID пропущенных
+	var postEdges []*model.PostEdge
+	var hasNextPage bool
+	cursor := after
+	for {
+		need := *first - int32(len(postEdges))
+		posts, more, newCursor, err := r.Post_.GetPosts(ctx, &need, cursor, desc)
 		if err != nil {
-			r.Log.Info("failed to find cursor",
+			r.Log.Error("failed to get posts",
 				slog.String("op", op),
-				slog.String("cursor", *after),
 				slog.String("error", err.Error()),
 			)
-			return nil, fmt.Errorf("%s: failed to find cursor: %w", op, err)
+			return nil, fmt.Errorf("%s: failed to get posts: %w", op, err)
 		}
+
+		for i := range *posts {
+			node := (*posts)[i]
+			if node.Private && !r.canAccessPrivate(ctx, &node) {
+				continue
+			}
+			postEdges = append(postEdges,
+				&model.PostEdge{
+					Cursor: node.ID,
+					Node:   &node,
+				})
+		}
+
+		if !more || len(postEdges) == int(*first) {
+			hasNextPage = more
+			break
+		}
+		cursor = &newCursor
+	}
+
+	var endCursor string
+	if len(postEdges) > 0 {
+		endCursor = postEdges[len(postEdges)-1].Cursor
+	}
+
+	r.Log.Info("posts was get successfully")
+	return &model.PostConnection{
+		Edges: postEdges,
+		PageInfo: &model.PageInfo{
+			EndCursor:   &endCursor,
+			HasNextPage: hasNextPage,
+		},
+	}, nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	const op = "graph.schema.resolvers.Me"
+
+	current, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, nil
 	}
 
-	comments, hasNextPage, newCursor, err := r.Comment_.GetComments(ctx, first, after, id)
+	user, err := r.loadUser(ctx, current.ID)
 	if err != nil {
-		r.Log.Error("failed to get comments",
+		return nil, fmt.Errorf("%s: failed to get user: %w", op, err)
+	}
+	return user, nil
+}
+
+// GetPost is the resolver for the getPost field.
+func (r *queryResolver) GetPost(ctx context.Context, id string, first *int32, after *string, maxDepth *int32) (*model.Post, error) {
+	const op = "graph.schema.resolvers.GetPost"
+
+	if first != nil && *first < 0 {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`first` cannot be less than 0"))
+	}
+	if maxDepth != nil && *maxDepth < 0 {
+		return nil, fmt.Errorf("%s: %w", op, apperr.Validation("`maxDepth` cannot be less than 0"))
+	}
+
+	post, err := r.Post_.GetPost(ctx, id)
+	if err != nil {
+		r.Log.Error("failed to get post",
 			slog.String("op", op),
-			slog.String("postID", id),
 			slog.String("error", err.Error()),
 		)
-		return nil, fmt.Errorf("%s: failed to get comments: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to get post: %w", op, err)
 	}
-
-	pInfo := &model.PageInfo{
-		EndCursor:   &newCursor,
-		HasNextPage: hasNextPage,
+	if post.Private && !r.canAccessPrivate(ctx, post) {
+		r.Log.Info("unauthorized access to private post",
+			slog.String("op", op),
+			slog.String("postID", id),
+		)
+		return nil, apperr.ErrAccessDenied
 	}
 
-	var comEdges []*model.CommentEdge
-	for _, com := range *comments {
-		cursor := com.ID
-		node := &model.Comment{
-			ID:        com.ID,
-			PostID:    com.PostID,
-			ParentID:  com.ParentID,
-			Content:   com.Content,
-			CreatedAt: com.CreatedAt,
+	// аргументы пагинации getPost сохранены для совместимости:
+	// комментарии загружаются заранее, и поле comments без аргументов вернет их
+	if first != nil {
+		withTotal := isFieldRequested(ctx, "comments", "totalCount")
+		post.Comments, err = r.loadComments(ctx, id, first, after, maxDepth, withTotal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		comEdges = append(comEdges,
-			&model.CommentEdge{
-				Cursor: cursor,
-				Node:   node,
-			})
-	}
-
-	comConnection := &model.CommentConnection{
-		Edges:    comEdges,
-		PageInfo: pInfo,
 	}
 
-	post.Comments = comConnection
-
 	r.Log.Info("post was get successfully",
 		slog.String("postID", id),
 	)
@@ -248,44 +628,54 @@ func (r *queryResolver) GetPost(ctx context.Context, id string, first *int32, af
 func (r *subscriptionResolver) CommentsUpdated(ctx context.Context, postID string) (<-chan *model.CommentNotify, error) {
 	const op = "graph.schema.resolvers.CommentsUpdated"
 
-	r.Log.Info("%s: new subscription - %s", op, postID)
-
-	clientChannel := make(chan *model.CommentNotify, 1)
-	go func() {
-		defer close(clientChannel)
-		defer r.Log.Info("%s: subscription closed - %s", op, postID)
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case notify := <-r.CommentAdded:
-				if notify.PostID == postID {
-					r.Log.Debug("%s: try to send notify: %s", op, postID)
-					select {
-					case clientChannel <- notify:
-						r.Log.Debug("%s: notify sended: %s", op, postID)
-					case <-ctx.Done():
-						return
-					}
-				} else {
-				}
-			}
-		}
-	}()
+	post, err := r.Post_.GetPost(ctx, postID)
+	if err != nil {
+		r.Log.Info("failed to get post for subscription",
+			slog.String("op", op),
+			slog.String("postID", postID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to get post: %w", op, err)
+	}
+	if post.Private && !r.canAccessPrivate(ctx, post) {
+		r.Log.Info("unauthorized subscription to private post",
+			slog.String("op", op),
+			slog.String("postID", postID),
+		)
+		return nil, apperr.ErrAccessDenied
+	}
 
-	return clientChannel, nil
+	notifies, err := r.Notifier.Subscribe(ctx, postID)
+	if err != nil {
+		r.Log.Error("failed to subscribe",
+			slog.String("op", op),
+			slog.String("postID", postID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("%s: failed to subscribe: %w", op, err)
+	}
+
+	r.Log.Info("new subscription", slog.String("postID", postID))
+	return notifies, nil
 }
 
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Post returns PostResolver implementation.
+func (r *Resolver) Post() PostResolver { return &postResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+type commentResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+)
+
+func newCommentConnection(comments []model.Comment, hasNextPage bool, endCursor string) *model.CommentConnection {
+	var comEdges []*model.CommentEdge
+	for i := range comments {
+		node := comments[i]
+		comEdges = append(comEdges,
+			&model.CommentEdge{
+				Cursor: node.ID,
+				Node:   &node,
+			})
+	}
+
+	return &model.CommentConnection{
+		Edges: comEdges,
+		PageInfo: &model.PageInfo{
+			EndCursor:   &endCursor,
+			HasNextPage: hasNextPage,
+		},
+	}
+}
+
+// attachThread раскладывает потомков корневых комментариев соединения по полям Replies.
+// Ответы на один комментарий в thread должны идти в порядке создания, на каждый комментарий - не больше limit+1 ответов:
+// лишний ответ отбрасывается и означает следующую страницу, которая загружается через поле replies с курсором.
+// Комментарии на глубине maxDepth остаются без Replies: их ответы загружаются отдельно при запросе.
+func attachThread(conn *model.CommentConnection, thread []model.Comment, maxDepth int, limit int32) {
+	byParent := make(map[string][]model.Comment)
+	for _, c := range thread {
+		if c.ParentID != nil {
+			byParent[*c.ParentID] = append(byParent[*c.ParentID], c)
+		}
+	}
+
+	var attach func(edges []*model.CommentEdge, level int)
+	attach = func(edges []*model.CommentEdge, level int) {
+		if level >= maxDepth {
+			return
+		}
+		for _, edge := range edges {
+			replies := byParent[edge.Node.ID]
+			hasNextPage := len(replies) > int(limit)
+			if hasNextPage {
+				replies = replies[:limit]
+			}
+
+			var endCursor string
+			if len(replies) > 0 {
+				endCursor = replies[len(replies)-1].ID
+			}
+			edge.Node.Replies = newCommentConnection(replies, hasNextPage, endCursor)
+			attach(edge.Node.Replies.Edges, level+1)
+		}
+	}
+
+	attach(conn.Edges, 0)
+}
+
+// paginateConnection применяет first/after к заранее загруженному соединению
+func paginateConnection(conn *model.CommentConnection, first *int32, after *string) (*model.CommentConnection, error) {
+	edges := conn.Edges
+
+	if after != nil && *after != "" {
+		isFound := false
+		for i, edge := range edges {
+			if edge.Cursor == *after {
+				edges = edges[i+1:]
+				isFound = true
+				break
+			}
+		}
+		if !isFound {
+			return nil, apperr.ErrInvalidCursor
+		}
+	}
+
+	hasNextPage := conn.PageInfo.HasNextPage
+	if first != nil && int(*first) < len(edges) {
+		edges = edges[:*first]
+		hasNextPage = true
+	}
+
+	var endCursor string
+	if len(edges) > 0 {
+		endCursor = edges[len(edges)-1].Cursor
+	}
+
+	return &model.CommentConnection{
+		TotalCount: conn.TotalCount,
+		Edges:      edges,
+		PageInfo: &model.PageInfo{
+			EndCursor:   &endCursor,
+			HasNextPage: hasNextPage,
+		},
+	}, nil
+}
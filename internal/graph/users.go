@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/auth"
+	"client-services/internal/graph/model"
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minUsernameLen = 3
+	maxUsernameLen = 32
+	minPasswordLen = 8
+)
+
+func validateCredentials(username string, password string) error {
+	if n := utf8.RuneCountInString(username); n < minUsernameLen || n > maxUsernameLen {
+		return apperr.Validation("username must have from %d to %d chars", minUsernameLen, maxUsernameLen)
+	}
+	if strings.ContainsAny(username, " \t\n") {
+		return apperr.Validation("username cannot contain spaces")
+	}
+	if utf8.RuneCountInString(password) < minPasswordLen {
+		return apperr.Validation("password must have %d chars or more", minPasswordLen)
+	}
+	return nil
+}
+
+func (r *Resolver) issueToken(user *model.User) (*model.AuthPayload, error) {
+	token, expiresAt, err := r.Auth.Issue(user.ID, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthPayload{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      user,
+	}, nil
+}
+
+// loadUser загружает пользователя, объединяя запросы одного запроса GraphQL в пакет
+func (r *Resolver) loadUser(ctx context.Context, id string) (*model.User, error) {
+	if loaders := loadersFrom(ctx); loaders != nil {
+		return loaders.Users.Load(ctx, id)
+	}
+
+	users, err := r.User_.GetUsers(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return users[id], nil
+}
+
+func authorIDFrom(ctx context.Context) *string {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	id := user.ID
+	return &id
+}
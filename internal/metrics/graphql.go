@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// метки операций с несколькими полями корня и запросов, которые не удалось разобрать
+const (
+	otherOperation   = "other"
+	unknownOperation = "unknown"
+)
+
+// GraphQL - расширение gqlgen, которое учитывает время и ошибки операций
+type GraphQL struct {
+	m *Metrics
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = GraphQL{}
+
+func (m *Metrics) GraphQL() GraphQL {
+	return GraphQL{m: m}
+}
+
+func (GraphQL) ExtensionName() string {
+	return "Metrics"
+}
+
+func (GraphQL) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse вызывается для каждого ответа операции.
+// Время учитывается только для запросов и мутаций: подписка отвечает много раз, пока открыто соединение,
+// поэтому для нее учитываются только ответы с ошибками.
+func (e GraphQL) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	oc := graphql.GetOperationContext(ctx)
+	opType, opName := operationLabels(oc)
+
+	if opType != string(ast.Subscription) && !oc.Stats.OperationStart.IsZero() {
+		e.m.graphqlDuration.WithLabelValues(opType, opName).Observe(time.Since(oc.Stats.OperationStart).Seconds())
+	}
+	if resp != nil && len(resp.Errors) > 0 {
+		e.m.graphqlErrors.WithLabelValues(opType, opName).Inc()
+	}
+
+	return resp
+}
+
+// operationLabels возвращает тип операции и поле корня, которое она запрашивает.
+// Имя операции выбирает клиент, поэтому в метки оно не попадает: иначе число рядов метрик не ограничено.
+// Поля корня проверены по схеме, и их набор конечен; операция с несколькими полями или фрагментом в корне
+// получает метку other, а запрос, который не прошел разбор, - unknown.
+func operationLabels(oc *graphql.OperationContext) (string, string) {
+	if oc.Operation == nil {
+		return unknownOperation, unknownOperation
+	}
+
+	field := otherOperation
+	if sel := oc.Operation.SelectionSet; len(sel) == 1 {
+		if f, ok := sel[0].(*ast.Field); ok {
+			field = f.Name
+		}
+	}
+	return string(oc.Operation.Operation), field
+}
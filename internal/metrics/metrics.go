@@ -0,0 +1,187 @@
+// Package metrics - метрики Prometheus сервиса и обертки, которые их собирают.
+package metrics
+
+import (
+	"client-services/internal/storage/cache"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "client_services"
+
+// Metrics хранит метрики сервиса в собственном реестре, чтобы экземпляры в тестах не конфликтовали
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	graphqlDuration *prometheus.HistogramVec
+	graphqlErrors   *prometheus.CounterVec
+
+	storageDuration *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+	retries         prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		graphqlDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "graphql",
+			Name:      "operation_duration_seconds",
+			Help:      "GraphQL query and mutation latency by operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type", "operation"}),
+		graphqlErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "graphql",
+			Name:      "errors_total",
+			Help:      "Number of GraphQL responses with errors by operation.",
+		}, []string{"type", "operation"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "call_duration_seconds",
+			Help:      "Storage call latency by repository and method.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 9),
+		}, []string{"repository", "method"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "errors_total",
+			Help:      "Number of failed storage calls by repository, method and error code.",
+		}, []string{"repository", "method", "code"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "retries_total",
+			Help:      "Number of storage transaction retries after transient errors.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.graphqlDuration,
+		m.graphqlErrors,
+		m.storageDuration,
+		m.storageErrors,
+		m.retries,
+	)
+
+	return m
+}
+
+// Handler отдает метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTP учитывает завершенный HTTP-запрос
+func (m *Metrics) ObserveHTTP(route, method string, status int, duration time.Duration) {
+	m.CountHTTP(route, method, status)
+	m.httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// CountHTTP учитывает HTTP-запрос без его длительности
+func (m *Metrics) CountHTTP(route, method string, status int) {
+	m.httpRequests.WithLabelValues(route, method, statusLabel(status)).Inc()
+}
+
+// ObserveRetry учитывает повтор транзакции хранилища
+func (m *Metrics) ObserveRetry() {
+	m.retries.Inc()
+}
+
+// Subscriptions - источник числа подписок и отброшенных уведомлений, например notify.Hub
+type Subscriptions interface {
+	Subscribers() int
+	Dropped() uint64
+}
+
+// RegisterSubscriptions добавляет число активных подписок и отброшенных уведомлений
+func (m *Metrics) RegisterSubscriptions(s Subscriptions) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "notifications",
+			Name:      "subscribers",
+			Help:      "Number of active comment subscriptions.",
+		}, func() float64 {
+			return float64(s.Subscribers())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "notifications",
+			Name:      "dropped_total",
+			Help:      "Number of notifications dropped because a subscriber buffer was full.",
+		}, func() float64 {
+			return float64(s.Dropped())
+		}),
+	)
+}
+
+// RegisterCache добавляет попадания и промахи кэша хранилища
+func (m *Metrics) RegisterCache(c *cache.Cache) {
+	m.registry.MustRegister(&cacheCollector{
+		cache: c,
+		hits: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_cache", "hits_total"),
+			"Number of storage cache hits by entry kind.", []string{"kind"}, nil),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(namespace, "storage_cache", "misses_total"),
+			"Number of storage cache misses by entry kind.", []string{"kind"}, nil),
+	})
+}
+
+// cacheCollector читает счетчики кэша при каждом сборе метрик
+type cacheCollector struct {
+	cache  *cache.Cache
+	hits   *prometheus.Desc
+	misses *prometheus.Desc
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	for kind, s := range map[string]cache.Stats{
+		"post":         stats.Posts,
+		"all_posts":    stats.AllPosts,
+		"comment_page": stats.Pages,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), kind)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), kind)
+	}
+}
+
+// statusLabel возвращает код ответа; если обработчик ничего не записал, сервер ответил 200
+func statusLabel(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/config"
+	"client-services/internal/graph/mocks"
+	"client-services/internal/graph/model"
+	"client-services/internal/storage/cache"
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestMetrics_Storage(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	postsMock := mocks.NewMockPostInterface(ctrl)
+
+	m := New()
+	posts, _, _ := m.WrapStorage(postsMock, mocks.NewMockCommentInterface(ctrl), mocks.NewMockUserInterface(ctrl))
+
+	postsMock.EXPECT().GetPost(ctx, "1").Return(&model.Post{ID: "1"}, nil)
+	postsMock.EXPECT().GetPost(ctx, "2").Return(nil, apperr.ErrPostNotFound)
+
+	post, err := posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "1", post.ID)
+	_, err = posts.GetPost(ctx, "2")
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+
+	require.Equal(t, 1, testutil.CollectAndCount(m.storageDuration))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("posts", "GetPost", string(apperr.CodeNotFound))))
+
+	m.ObserveRetry()
+	require.Equal(t, 1.0, testutil.ToFloat64(m.retries))
+}
+
+type testSubscriptions struct{}
+
+func (testSubscriptions) Subscribers() int { return 3 }
+func (testSubscriptions) Dropped() uint64  { return 7 }
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.RegisterSubscriptions(testSubscriptions{})
+
+	c, err := cache.New(&config.StorageCache{Posts: 10, Pages: 10, TTL: time.Minute})
+	require.NoError(t, err)
+	m.RegisterCache(c)
+	ctrl := gomock.NewController(t)
+	postsMock := mocks.NewMockPostInterface(ctrl)
+	posts, _ := c.Wrap(postsMock, mocks.NewMockCommentInterface(ctrl))
+	postsMock.EXPECT().GetPost(gomock.Any(), "1").Return(&model.Post{ID: "1"}, nil)
+	for i := 0; i < 2; i++ {
+		_, err := posts.GetPost(context.Background(), "1")
+		require.NoError(t, err)
+	}
+
+	m.ObserveHTTP("/query", "POST", 0, time.Millisecond)
+	m.CountHTTP("/query", "GET", 101)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"client_services_notifications_subscribers 3",
+		"client_services_notifications_dropped_total 7",
+		`client_services_storage_cache_hits_total{kind="post"} 1`,
+		`client_services_storage_cache_misses_total{kind="post"} 1`,
+		`client_services_http_requests_total{method="POST",route="/query",status="200"} 1`,
+		`client_services_http_requests_total{method="GET",route="/query",status="101"} 1`,
+		`client_services_http_request_duration_seconds_count{method="POST",route="/query"} 1`,
+		"go_goroutines",
+	} {
+		require.Contains(t, string(body), line)
+	}
+	require.NotContains(t, string(body), `client_services_http_request_duration_seconds_count{method="GET"`)
+}
+
+func TestGraphQL_InterceptResponse(t *testing.T) {
+	m := New()
+	ext := m.GraphQL()
+
+	withOperation := func(op *ast.OperationDefinition) context.Context {
+		oc := &graphql.OperationContext{Operation: op}
+		oc.Stats.OperationStart = time.Now()
+		return graphql.WithOperationContext(context.Background(), oc)
+	}
+	ok := func(ctx context.Context) *graphql.Response { return &graphql.Response{} }
+	failed := func(ctx context.Context) *graphql.Response {
+		return &graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("failed")}}
+	}
+
+	fields := func(names ...string) ast.SelectionSet {
+		var set ast.SelectionSet
+		for _, name := range names {
+			set = append(set, &ast.Field{Name: name, Alias: name})
+		}
+		return set
+	}
+
+	// имя операции выбирает клиент, поэтому метка берется из поля схемы
+	ext.InterceptResponse(withOperation(&ast.OperationDefinition{Operation: ast.Query, Name: "Client1", SelectionSet: fields("getPost")}), ok)
+	ext.InterceptResponse(withOperation(&ast.OperationDefinition{Operation: ast.Query, Name: "Client2", SelectionSet: fields("getPost")}), ok)
+	ext.InterceptResponse(withOperation(&ast.OperationDefinition{Operation: ast.Mutation, SelectionSet: fields("createPost", "createComment")}), failed)
+	// ответы подписки учитываются только при ошибках
+	ext.InterceptResponse(withOperation(&ast.OperationDefinition{Operation: ast.Subscription, Name: "OnComment", SelectionSet: fields("commentsUpdated")}), ok)
+	ext.InterceptResponse(withOperation(&ast.OperationDefinition{Operation: ast.Subscription, Name: "OnComment", SelectionSet: fields("commentsUpdated")}), failed)
+	// запрос, который не удалось разобрать
+	ext.InterceptResponse(withOperation(nil), failed)
+	// без контекста операции
+	ext.InterceptResponse(context.Background(), ok)
+
+	// время учитывается для запроса, мутации и неразобранного запроса
+	require.Equal(t, 3, testutil.CollectAndCount(m.graphqlDuration))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.graphqlErrors.WithLabelValues("query", "getPost")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.graphqlErrors.WithLabelValues("mutation", otherOperation)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.graphqlErrors.WithLabelValues("subscription", "commentsUpdated")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.graphqlErrors.WithLabelValues(unknownOperation, unknownOperation)))
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"context"
+	"time"
+)
+
+// observe учитывает время и ошибку вызова хранилища; вызывается через defer
+func (m *Metrics) observe(repository, method string, start time.Time, err *error) {
+	m.storageDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		m.storageErrors.WithLabelValues(repository, method, string(apperr.CodeOf(*err))).Inc()
+	}
+}
+
+// WrapStorage возвращает декораторы, которые учитывают время вызовов хранилища
+func (m *Metrics) WrapStorage(posts graph.PostInterface, comments graph.CommentInterface, users graph.UserInterface) (*Posts, *Comments, *Users) {
+	return &Posts{next: posts, m: m}, &Comments{next: comments, m: m}, &Users{next: users, m: m}
+}
+
+// Posts - декоратор PostInterface, который учитывает время вызовов
+type Posts struct {
+	next graph.PostInterface
+	m    *Metrics
+}
+
+var _ graph.PostInterface = (*Posts)(nil)
+
+func (p *Posts) SavePost(ctx context.Context, post *model.Post) (_ string, _ time.Time, err error) {
+	defer p.m.observe("posts", "SavePost", time.Now(), &err)
+	return p.next.SavePost(ctx, post)
+}
+
+func (p *Posts) GetPost(ctx context.Context, id string) (_ *model.Post, err error) {
+	defer p.m.observe("posts", "GetPost", time.Now(), &err)
+	return p.next.GetPost(ctx, id)
+}
+
+func (p *Posts) GetAllPosts(ctx context.Context) (_ []model.Post, err error) {
+	defer p.m.observe("posts", "GetAllPosts", time.Now(), &err)
+	return p.next.GetAllPosts(ctx)
+}
+
+func (p *Posts) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (_ *[]model.Post, _ bool, _ string, err error) {
+	defer p.m.observe("posts", "GetPosts", time.Now(), &err)
+	return p.next.GetPosts(ctx, first, after, desc)
+}
+
+func (p *Posts) UpdatePost(ctx context.Context, id string, title *string, content *string) (_ *model.Post, err error) {
+	defer p.m.observe("posts", "UpdatePost", time.Now(), &err)
+	return p.next.UpdatePost(ctx, id, title, content)
+}
+
+func (p *Posts) DeletePost(ctx context.Context, id string) (err error) {
+	defer p.m.observe("posts", "DeletePost", time.Now(), &err)
+	return p.next.DeletePost(ctx, id)
+}
+
+func (p *Posts) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (_ *model.Post, err error) {
+	defer p.m.observe("posts", "SetCommentsAllowed", time.Now(), &err)
+	return p.next.SetCommentsAllowed(ctx, id, allowed)
+}
+
+// Comments - декоратор CommentInterface, который учитывает время вызовов
+type Comments struct {
+	next graph.CommentInterface
+	m    *Metrics
+}
+
+var _ graph.CommentInterface = (*Comments)(nil)
+
+func (c *Comments) SaveComment(ctx context.Context, comment *model.Comment) (_ string, _ time.Time, err error) {
+	defer c.m.observe("comments", "SaveComment", time.Now(), &err)
+	return c.next.SaveComment(ctx, comment)
+}
+
+func (c *Comments) GetComments(ctx context.Context, first *int32, after *string, postID string) (_ *[]model.Comment, _ bool, _ string, err error) {
+	defer c.m.observe("comments", "GetComments", time.Now(), &err)
+	return c.next.GetComments(ctx, first, after, postID)
+}
+
+func (c *Comments) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (_ map[string]*model.CommentsPage, err error) {
+	defer c.m.observe("comments", "GetCommentsBatch", time.Now(), &err)
+	return c.next.GetCommentsBatch(ctx, first, postIDs)
+}
+
+func (c *Comments) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (_ *[]model.Comment, _ bool, _ string, err error) {
+	defer c.m.observe("comments", "GetReplies", time.Now(), &err)
+	return c.next.GetReplies(ctx, first, after, postID, parentID)
+}
+
+func (c *Comments) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) (_ []model.Comment, err error) {
+	defer c.m.observe("comments", "GetThread", time.Now(), &err)
+	return c.next.GetThread(ctx, postID, rootIDs, maxDepth, limit)
+}
+
+func (c *Comments) CountComments(ctx context.Context, postID string) (_ int32, err error) {
+	defer c.m.observe("comments", "CountComments", time.Now(), &err)
+	return c.next.CountComments(ctx, postID)
+}
+
+func (c *Comments) IsCommentExist(ctx context.Context, commentID string, postID string) (err error) {
+	defer c.m.observe("comments", "IsCommentExist", time.Now(), &err)
+	return c.next.IsCommentExist(ctx, commentID, postID)
+}
+
+func (c *Comments) GetComment(ctx context.Context, id string) (_ *model.Comment, err error) {
+	defer c.m.observe("comments", "GetComment", time.Now(), &err)
+	return c.next.GetComment(ctx, id)
+}
+
+func (c *Comments) UpdateComment(ctx context.Context, id string, content string) (_ *model.Comment, err error) {
+	defer c.m.observe("comments", "UpdateComment", time.Now(), &err)
+	return c.next.UpdateComment(ctx, id, content)
+}
+
+func (c *Comments) DeleteComment(ctx context.Context, id string) (_ *model.Comment, err error) {
+	defer c.m.observe("comments", "DeleteComment", time.Now(), &err)
+	return c.next.DeleteComment(ctx, id)
+}
+
+// Users - декоратор UserInterface, который учитывает время вызовов
+type Users struct {
+	next graph.UserInterface
+	m    *Metrics
+}
+
+var _ graph.UserInterface = (*Users)(nil)
+
+func (u *Users) SaveUser(ctx context.Context, user *model.User, passwordHash string) (_ string, _ time.Time, err error) {
+	defer u.m.observe("users", "SaveUser", time.Now(), &err)
+	return u.next.SaveUser(ctx, user, passwordHash)
+}
+
+func (u *Users) GetUserByName(ctx context.Context, username string) (_ *model.User, _ string, err error) {
+	defer u.m.observe("users", "GetUserByName", time.Now(), &err)
+	return u.next.GetUserByName(ctx, username)
+}
+
+func (u *Users) GetUsers(ctx context.Context, ids []string) (_ map[string]*model.User, err error) {
+	defer u.m.observe("users", "GetUsers", time.Now(), &err)
+	return u.next.GetUsers(ctx, ids)
+}
+
+func (u *Users) SetUserRole(ctx context.Context, id string, role model.Role) (_ *model.User, err error) {
+	defer u.m.observe("users", "SetUserRole", time.Now(), &err)
+	return u.next.SetUserRole(ctx, id, role)
+}
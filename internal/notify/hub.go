@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"client-services/internal/graph/model"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Hub - внутрипроцессная шина уведомлений.
+// Каждое опубликованное событие доставляется всем активным подпискам на пост.
+// У каждого подписчика свой буфер; если подписчик не успевает читать и буфер заполнен,
+// новое событие для него отбрасывается (остальные подписчики его получают), счетчик Dropped увеличивается.
+type Hub struct {
+	log     *slog.Logger
+	bufSize int
+
+	mu   sync.RWMutex
+	subs map[string]map[*subscriber]struct{}
+
+	dropped atomic.Uint64
+}
+
+type subscriber struct {
+	ch chan *model.CommentNotify
+}
+
+const defaultBufferSize = 16
+
+func NewHub(log *slog.Logger, bufSize int) *Hub {
+	if bufSize <= 0 {
+		bufSize = defaultBufferSize
+	}
+
+	return &Hub{
+		log:     log,
+		bufSize: bufSize,
+		subs:    make(map[string]map[*subscriber]struct{}),
+	}
+}
+
+// Publish рассылает уведомление всем подписчикам поста, не блокируясь на медленных подписчиках.
+func (h *Hub) Publish(ctx context.Context, n *model.CommentNotify) error {
+	const op = "notify.hub.Publish"
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for s := range h.subs[n.PostID] {
+		select {
+		case s.ch <- n:
+		default:
+			h.dropped.Add(1)
+			h.log.Warn("subscriber buffer is full, notify dropped",
+				slog.String("op", op),
+				slog.String("postID", n.PostID),
+				slog.String("commentID", n.ID),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe регистрирует подписчика на пост. Канал закрывается после отмены ctx.
+func (h *Hub) Subscribe(ctx context.Context, postID string) (<-chan *model.CommentNotify, error) {
+	s := &subscriber{ch: make(chan *model.CommentNotify, h.bufSize)}
+
+	h.mu.Lock()
+	if _, ok := h.subs[postID]; !ok {
+		h.subs[postID] = make(map[*subscriber]struct{})
+	}
+	h.subs[postID][s] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.unsubscribe(postID, s)
+	}()
+
+	return s.ch, nil
+}
+
+func (h *Hub) unsubscribe(postID string, s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs[postID], s)
+	if len(h.subs[postID]) == 0 {
+		delete(h.subs, postID)
+	}
+	close(s.ch)
+}
+
+// Dropped возвращает количество уведомлений, отброшенных из-за переполненных буферов подписчиков.
+func (h *Hub) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Subscribers возвращает количество активных подписок.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, s := range h.subs {
+		count += len(s)
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"client-services/internal/graph/model"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHub(bufSize int) *Hub {
+	return NewHub(slog.New(slog.NewTextHandler(io.Discard, nil)), bufSize)
+}
+
+func TestHub_Publish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := newTestHub(4)
+
+	first, err := hub.Subscribe(ctx, "p-0")
+	require.NoError(t, err)
+	second, err := hub.Subscribe(ctx, "p-0")
+	require.NoError(t, err)
+	other, err := hub.Subscribe(ctx, "p-1")
+	require.NoError(t, err)
+	require.Equal(t, 3, hub.Subscribers())
+
+	n := &model.CommentNotify{PostID: "p-0", ID: "c-0", Event: model.NotifyEventCommentAdded}
+	require.NoError(t, hub.Publish(ctx, n))
+
+	require.Equal(t, n, <-first)
+	require.Equal(t, n, <-second)
+	select {
+	case got := <-other:
+		t.Fatalf("notify for another post delivered: %v", got)
+	default:
+	}
+}
+
+func TestHub_DropOnFullBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := newTestHub(2)
+
+	slow, err := hub.Subscribe(ctx, "p-0")
+	require.NoError(t, err)
+	fast, err := hub.Subscribe(ctx, "p-0")
+	require.NoError(t, err)
+
+	// медленный подписчик не читает: третье и четвертое событие для него отбрасываются
+	for i := 0; i < 4; i++ {
+		require.NoError(t, hub.Publish(ctx, &model.CommentNotify{PostID: "p-0", ID: string(rune('a' + i))}))
+		if i < 3 {
+			<-fast
+		}
+	}
+	require.Equal(t, uint64(2), hub.Dropped())
+
+	require.Equal(t, "a", (<-slow).ID)
+	require.Equal(t, "b", (<-slow).ID)
+	// быстрый подписчик получил все события
+	require.Equal(t, "d", (<-fast).ID)
+}
+
+func TestHub_UnsubscribeOnCancel(t *testing.T) {
+	hub := newTestHub(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := hub.Subscribe(ctx, "p-0")
+	require.NoError(t, err)
+	require.Equal(t, 1, hub.Subscribers())
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed after cancel")
+	}
+	require.Equal(t, 0, hub.Subscribers())
+
+	// публикация без подписчиков не отбрасывает события и не паникует
+	require.NoError(t, hub.Publish(context.Background(), &model.CommentNotify{PostID: "p-0"}))
+	require.Zero(t, hub.Dropped())
+}
@@ -0,0 +1,83 @@
+package run
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage/postgres"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+const migrateUsage = "usage: client-services migrate [up | down [steps] | version]"
+
+// Migrate выполняет подкоманду migrate:
+//   - up - применяет все недостающие миграции (по умолчанию);
+//   - down [steps] - откатывает steps последних миграций, по умолчанию одну;
+//   - version - выводит номер последней примененной миграции.
+func Migrate(cfg *config.Config, log *slog.Logger, args []string) error {
+	const op = "run.Migrate"
+
+	if cfg.Storage != "postgres" {
+		return fmt.Errorf("%s: migrations are supported only by postgres storage, got %q", op, cfg.Storage)
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	steps := 1
+	switch {
+	case command == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("%s: invalid steps %q: %s", op, args[1], migrateUsage)
+		}
+		steps = n
+	case len(args) > 1:
+		return fmt.Errorf("%s: %s", op, migrateUsage)
+	}
+
+	if cfg.StorageConnect == nil {
+		return fmt.Errorf("%s: storage_connect section is missing in the config file", op)
+	}
+
+	storage, err := postgres.Connect(*cfg.StorageConnect)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer storage.CloseDB()
+
+	migrator, err := postgres.NewMigrator(storage, log)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx, steps)
+	case "version":
+		var version int64
+		version, err = migrator.Version(ctx)
+		if err == nil {
+			fmt.Println(version)
+		}
+	default:
+		return fmt.Errorf("%s: unknown command %q: %s", op, command, migrateUsage)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("migrate command completed", slog.String("command", command))
+	return nil
+}
@@ -0,0 +1,21 @@
+package run
+
+import (
+	"client-services/internal/config"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_Config(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	err := Migrate(&config.Config{Storage: "in-memory"}, log, nil)
+	require.ErrorContains(t, err, "supported only by postgres")
+
+	// без секции storage_connect команда завершается ошибкой, а не паникой
+	err = Migrate(&config.Config{Storage: "postgres"}, log, nil)
+	require.ErrorContains(t, err, "storage_connect section is missing")
+}
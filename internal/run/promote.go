@@ -0,0 +1,51 @@
+package run
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"client-services/internal/notify"
+	"client-services/internal/storage"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+const promoteUsage = "usage: client-services promote <username>"
+
+// Promote выполняет подкоманду promote: назначает роль ADMIN уже зарегистрированному пользователю.
+// Первого администратора назначает оператор сервиса, а не регистрация под заранее известным именем.
+func Promote(cfg *config.Config, log *slog.Logger, args []string) error {
+	const op = "run.Promote"
+
+	if len(args) != 1 {
+		return fmt.Errorf("%s: %s", op, promoteUsage)
+	}
+
+	hub := notify.NewHub(log, cfg.Notifications.BufferSize)
+	backend, err := storage.Open(cfg.Storage, cfg, storage.Deps{Log: log, Hub: hub})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer backend.Storage.CloseDB()
+
+	user, err := promoteAdmin(context.Background(), backend.Users, args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user promoted to admin",
+		slog.String("userID", user.ID),
+		slog.String("username", user.Username),
+	)
+	return nil
+}
+
+func promoteAdmin(ctx context.Context, users graph.UserInterface, username string) (*model.User, error) {
+	user, _, err := users.GetUserByName(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %q: %w", username, err)
+	}
+
+	return users.SetUserRole(ctx, user.ID, model.RoleAdmin)
+}
@@ -0,0 +1,41 @@
+package run
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/config"
+	"client-services/internal/graph/model"
+	in_memory "client-services/internal/storage/in-memory"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoteAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := in_memory.Open(&config.InMemory{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer s.CloseDB()
+	users := s.NewUserStorage()
+
+	id, _, err := users.SaveUser(ctx, &model.User{Username: "alice", Role: model.RoleUser}, "hash")
+	require.NoError(t, err)
+
+	user, err := promoteAdmin(ctx, users, "alice")
+	require.NoError(t, err)
+	require.Equal(t, id, user.ID)
+	require.Equal(t, model.RoleAdmin, user.Role)
+
+	_, err = promoteAdmin(ctx, users, "bob")
+	require.ErrorIs(t, err, apperr.ErrUserNotFound)
+}
+
+func TestPromote_Usage(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	err := Promote(&config.Config{Storage: "in-memory"}, log, nil)
+	require.ErrorContains(t, err, promoteUsage)
+}
@@ -1,23 +1,31 @@
 package run
 
 import (
+	"client-services/internal/auth"
 	"client-services/internal/config"
 	"client-services/internal/graph"
-	"client-services/internal/graph/model"
 	uqmutex "client-services/internal/graph/unique-mutex"
+	"client-services/internal/metrics"
+	"client-services/internal/notify"
+	authmw "client-services/internal/server/middlewares/auth"
 	"client-services/internal/server/middlewares/logger"
+	metricsmw "client-services/internal/server/middlewares/metrics"
 	"client-services/internal/services"
-	in_memory "client-services/internal/storage/in-memory"
-	"client-services/internal/storage/postgres"
+	"client-services/internal/storage"
+	"client-services/internal/storage/cache"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
@@ -29,9 +37,15 @@ import (
 )
 
 func Run(cfg *config.Config, log *slog.Logger) {
-	router := initRouter(log)
+	// chi паникует на шаблоне маршрута, который не начинается с "/"; проверка до открытия хранилища
+	if cfg.Metrics.Enabled.Value() && !strings.HasPrefix(cfg.Metrics.Path, "/") {
+		log.Error("metrics path must start with /", slog.String("path", cfg.Metrics.Path))
+		os.Exit(1)
+	}
+
+	m := metrics.New()
 
-	resolver, err := initResolver(cfg)
+	resolver, err := initResolver(cfg, m)
 	if err != nil {
 		slog.Error("failed to init resolver",
 			slog.String("storage", cfg.Storage),
@@ -40,59 +54,80 @@ func Run(cfg *config.Config, log *slog.Logger) {
 		os.Exit(1)
 	}
 
-	srv := initGraphQL(cfg.QueryCache, resolver)
+	router := initRouter(log, resolver.Auth, m)
+
+	srv := initGraphQL(cfg.QueryCache, resolver, m)
 
 	router.Handle("/pground", playground.Handler("GraphQL playground", "/query"))
 	router.Handle("/query", srv)
+	if cfg.Metrics.Enabled.Value() {
+		router.Handle(cfg.Metrics.Path, m.Handler())
+	}
 
-	err = startServer(cfg.HTTPServer, router, log)
+	// сигналы перехватываются до запуска сервера, чтобы ни один не завершил процесс без остановки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = startServer(ctx, cfg.HTTPServer, router, log)
+	stop()
+	if closeErr := resolver.Storage.CloseDB(); closeErr != nil {
+		log.Error("failed to close storage", slog.String("error", closeErr.Error()))
+	}
 	if err != nil {
 		log.Error("server error", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 }
 
-func startServer(cfg *config.HTTPServer, router *chi.Mux, log *slog.Logger) error {
+// shutdownTimeout - время на завершение активных запросов при остановке сервера
+const shutdownTimeout = 5 * time.Second
+
+// startServer обслуживает запросы, пока не отменен ctx или сервер не завершился с ошибкой
+func startServer(ctx context.Context, cfg *config.HTTPServer, handler http.Handler, log *slog.Logger) error {
 	address := fmt.Sprintf(":%s", cfg.Port)
-	srv := &http.Server{
-		Addr:    address,
-		Handler: router,
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Error("failed to start http server", slog.String("error", err.Error()))
+		return err
 	}
 
+	log.Info("starting http server", slog.String("address", address))
+	return serve(ctx, &http.Server{Handler: handler}, listener, log)
+}
+
+// serve запускает srv на listener и при отмене ctx останавливает его, дожидаясь активных запросов
+func serve(ctx context.Context, srv *http.Server, listener net.Listener, log *slog.Logger) error {
 	errChan := make(chan error, 1)
 	go func() {
-		slog.Info("starting http server", slog.String("address", address))
-		var err error
-		if err = srv.ListenAndServe(); err != nil {
-			slog.Error("failed to start http server", slog.String("error", err.Error()))
-		}
-		errChan <- err
+		errChan <- srv.Serve(listener)
 	}()
 
-	if err := <-errChan; err != nil {
+	select {
+	case err := <-errChan:
+		log.Error("http server stopped unexpectedly", slog.String("error", err.Error()))
 		return err
+	case <-ctx.Done():
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
-	<-ctx.Done()
-	slog.Info("shutting down server...")
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	log.Info("shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		slog.Error("expected shutdown failed", slog.String("error", err.Error()))
+		log.Error("expected shutdown failed", slog.String("error", err.Error()))
 		return err
-	} else {
-		slog.Info("server stopped")
 	}
+	if err := <-errChan; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	log.Info("server stopped")
 	return nil
 }
 
-func initGraphQL(queryCache int, resolver *graph.Resolver) *handler.Server {
+func initGraphQL(queryCache int, resolver *graph.Resolver, m *metrics.Metrics) *handler.Server {
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
-		Resolvers: resolver,
+		Resolvers:  resolver,
+		Directives: graph.NewDirectives(resolver),
 	}))
 
 	srv.AddTransport(transport.GET{})
@@ -100,56 +135,90 @@ func initGraphQL(queryCache int, resolver *graph.Resolver) *handler.Server {
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.Websocket{
 		KeepAlivePingInterval: time.Second * 10,
+		InitFunc:              authmw.WebsocketInit(slog.Default(), resolver.Auth),
 	})
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](queryCache))
+	srv.SetErrorPresenter(graph.NewErrorPresenter(slog.Default()))
 	srv.Use(extension.Introspection{})
+	srv.Use(m.GraphQL())
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(graph.WithLoaders(services.WithSession(ctx), resolver))
+	})
 
 	slog.Info("graphql initialized successfully")
 	return srv
 }
 
-func initResolver(cfg *config.Config) (*graph.Resolver, error) {
-	var resolver *graph.Resolver
-
-	switch cfg.Storage {
-	case "in-memory":
-		storage := in_memory.NewStorage()
-		resolver = &graph.Resolver{
-			Log:          slog.Default(),
-			Storage:      storage,
-			Post_:        storage.NewPostStorage(),
-			Comment_:     storage.NewCommentStorage(),
-			UqMutex:      uqmutex.NewUqMutex(),
-			CommentAdded: make(chan *model.CommentNotify),
+func initResolver(cfg *config.Config, m *metrics.Metrics) (*graph.Resolver, error) {
+	hub := notify.NewHub(slog.Default(), cfg.Notifications.BufferSize)
+	m.RegisterSubscriptions(hub)
+
+	manager, err := auth.NewManager(&cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+
+	if cfg.Notifications.Backend != "local" && cfg.Notifications.Backend != cfg.Storage {
+		return nil, fmt.Errorf("notifications backend %q is not supported with %q storage",
+			cfg.Notifications.Backend, cfg.Storage)
+	}
+
+	backend, err := storage.Open(cfg.Storage, cfg, storage.Deps{Log: slog.Default(), Hub: hub, Metrics: m})
+	if err != nil {
+		return nil, err
+	}
+
+	var notifier graph.NotifierInterface = hub
+	if cfg.Notifications.Backend != "local" {
+		if backend.Notifier == nil {
+			backend.Storage.CloseDB()
+			return nil, fmt.Errorf("%q storage does not provide notifications", cfg.Storage)
 		}
-	case "postgres":
-		storage, err := postgres.NewStorage(*cfg.StorageConnect)
+		notifier = backend.Notifier
+	}
+
+	// кэш стоит перед метриками, чтобы учитывалось время только реальных вызовов хранилища
+	var posts graph.PostInterface
+	var comments graph.CommentInterface
+	var users graph.UserInterface
+	posts, comments, users = m.WrapStorage(backend.Posts, backend.Comments, backend.Users)
+	if cfg.StorageCache.Enabled {
+		c, err := cache.New(&cfg.StorageCache)
 		if err != nil {
-			return nil, fmt.Errorf("failed to initialize postgres database: %w", err)
+			backend.Storage.CloseDB()
+			return nil, err
 		}
+		posts, comments = c.Wrap(posts, comments)
+		m.RegisterCache(c)
+		slog.Info("storage cache enabled", slog.Duration("ttl", cfg.StorageCache.TTL))
+	}
 
-		resolver = &graph.Resolver{
-			Log:          slog.Default(),
-			Storage:      storage,
-			Post_:        services.NewPostService(&storage.DB),
-			Comment_:     services.NewCommentService(&storage.DB),
-			UqMutex:      uqmutex.NewUqMutex(),
-			CommentAdded: make(chan *model.CommentNotify),
-		}
-	default:
-		return nil, fmt.Errorf("unknown storage type")
+	resolver := &graph.Resolver{
+		Log:      slog.Default(),
+		Storage:  backend.Storage,
+		Post_:    posts,
+		Comment_: comments,
+		User_:    users,
+		Auth:     manager,
+		UqMutex:  uqmutex.NewUqMutex(),
+		Notifier: notifier,
 	}
 
-	slog.Info("resolver initialized successfully", slog.String("storage type", cfg.Storage))
+	slog.Info("resolver initialized successfully",
+		slog.String("storage type", cfg.Storage),
+		slog.String("notifications", cfg.Notifications.Backend),
+	)
 	return resolver, nil
 }
 
-func initRouter(log *slog.Logger) *chi.Mux {
+func initRouter(log *slog.Logger, manager *auth.Manager, m *metrics.Metrics) *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
+	router.Use(metricsmw.New(log, m))
 	router.Use(logger.New(log))
 	router.Use(middleware.Recoverer)
+	router.Use(authmw.New(log, manager))
 
 	slog.Info("router started")
 	return router
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"client-services/internal/config"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_Shutdown(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+
+	// активный запрос завершается до остановки сервера
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, &http.Server{Handler: handler}, listener, log)
+	}()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("server stopped before the active request finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.Equal(t, http.StatusNoContent, <-status)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after cancellation")
+	}
+
+	_, err = http.Get(url)
+	require.Error(t, err)
+}
+
+func TestStartServer_ListenError(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	busy, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer busy.Close()
+	_, port, err := net.SplitHostPort(busy.Addr().String())
+	require.NoError(t, err)
+
+	// порт занят: ошибка возвращается сразу, не дожидаясь сигнала
+	err = startServer(context.Background(), &config.HTTPServer{Port: port}, http.NotFoundHandler(), log)
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"client-services/internal/auth"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// New проверяет токен из заголовка Authorization и помещает пользователя в контекст запроса.
+// Запросы без заголовка проходят как анонимные, с недействительным токеном - отклоняются.
+func New(log *slog.Logger, manager *auth.Manager) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "server/middleware/auth"),
+		)
+		log.Info("middleware auth enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				http.Error(w, "invalid authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := manager.Parse(token)
+			if err != nil {
+				log.Info("invalid token",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("error", err.Error()),
+				)
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
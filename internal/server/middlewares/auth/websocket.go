@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"client-services/internal/auth"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+var errTokenExpired = errors.New("token expired")
+
+// WebsocketInit проверяет токен из payload сообщения connection_init.
+// Соединение без токена остается анонимным, с недействительным токеном - отклоняется.
+// Соединение аутентифицированного пользователя закрывается по истечении срока действия токена.
+func WebsocketInit(log *slog.Logger, manager *auth.Manager) transport.WebsocketInitFunc {
+	log = log.With(
+		slog.String("component", "server/middleware/auth"),
+	)
+
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		if header := initPayload.Authorization(); header != "" {
+			// токен может передаваться как с префиксом Bearer, так и без него
+			token := strings.TrimPrefix(header, "Bearer ")
+
+			user, err := manager.Parse(token)
+			if err != nil {
+				log.Info("invalid websocket token",
+					slog.String("error", err.Error()),
+				)
+				return nil, nil, fmt.Errorf("invalid token")
+			}
+			ctx = auth.WithUser(ctx, user)
+		}
+
+		// пользователь мог быть добавлен и middleware по заголовку запроса на установку соединения
+		user, ok := auth.UserFromContext(ctx)
+		if !ok {
+			return ctx, nil, nil
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		timer := time.AfterFunc(time.Until(user.ExpiresAt), func() {
+			log.Info("websocket token expired, closing connection",
+				slog.String("userID", user.ID),
+			)
+			cancel(errTokenExpired)
+		})
+		context.AfterFunc(ctx, func() { timer.Stop() })
+
+		return ctx, nil, nil
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"client-services/internal/metrics"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/websocket"
+)
+
+// unmatchedRoute - метка запросов, не совпавших ни с одним маршрутом; путь не используется, чтобы не плодить метки
+const unmatchedRoute = "unmatched"
+
+// New учитывает число и время HTTP-запросов по шаблону маршрута chi
+func New(log *slog.Logger, m *metrics.Metrics) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "server/middleware/metrics"),
+		)
+		log.Info("middleware metrics enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			wNew := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			tStart := time.Now()
+
+			defer func() {
+				route := unmatchedRoute
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+					route = rctx.RoutePattern()
+				}
+				// соединение websocket открыто все время подписки, поэтому его длительность не учитывается
+				if websocket.IsWebSocketUpgrade(r) {
+					status := wNew.Status()
+					if status == 0 {
+						// ответ на upgrade пишется в перехваченное соединение мимо ResponseWriter
+						status = http.StatusSwitchingProtocols
+					}
+					m.CountHTTP(route, r.Method, status)
+					return
+				}
+				m.ObserveHTTP(route, r.Method, wNew.Status(), time.Since(tStart))
+			}()
+
+			next.ServeHTTP(wNew, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage"
+	"client-services/internal/storage/postgres"
+	"fmt"
+)
+
+func init() {
+	storage.Register("postgres", newBackend)
+}
+
+// newBackend подключается к PostgreSQL и собирает сервисы поверх нее.
+// При notifications.backend = postgres уведомления раздаются между экземплярами через LISTEN/NOTIFY.
+func newBackend(cfg *config.Config, deps storage.Deps) (*storage.Backend, error) {
+	const op = "services.backend.newBackend"
+
+	if cfg.StorageConnect == nil {
+		return nil, fmt.Errorf("%s: storage_connect section is missing in the config file", op)
+	}
+
+	s, err := postgres.NewStorage(*cfg.StorageConnect)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := NewRetryPolicy(cfg.StorageConnect)
+	if deps.Metrics != nil {
+		retry.OnRetry = deps.Metrics.ObserveRetry
+	}
+	replicas := NewReplicas(s.Replicas, cfg.StorageConnect.ReadYourWrites.Value())
+
+	backend := &storage.Backend{
+		Storage:  s,
+		Posts:    NewPostService(&s.DB, replicas, retry),
+		Comments: NewCommentService(&s.DB, replicas, retry),
+		Users:    NewUserService(&s.DB, retry),
+	}
+
+	if cfg.Notifications.Backend == "postgres" {
+		backend.Notifier = postgres.NewNotifier(s, deps.Hub, deps.Log)
+	}
+
+	return backend, nil
+}
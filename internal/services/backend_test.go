@@ -0,0 +1,15 @@
+package services
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackend_MissingConfig(t *testing.T) {
+	// без секции storage_connect хранилище не запускается, а не падает с паникой
+	_, err := storage.Open("postgres", &config.Config{Storage: "postgres"}, storage.Deps{})
+	require.ErrorContains(t, err, "storage_connect section is missing")
+}
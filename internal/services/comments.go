@@ -1,6 +1,7 @@
 package services
 
 import (
+	"client-services/internal/apperr"
 	"client-services/internal/graph/model"
 	"context"
 	"errors"
@@ -8,17 +9,21 @@ import (
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 	"github.com/google/uuid"
 )
 
 type CommentService struct {
-	db *pg.DB
+	db       *pg.DB
+	replicas *Replicas
+	retry    RetryPolicy
+	now      func() time.Time
 }
 
-var ErrCommentNotFound = errors.New("comment not found")
+const foreignKeyViolation = "23503"
 
-func NewCommentService(db *pg.DB) *CommentService {
-	return &CommentService{db: db}
+func NewCommentService(db *pg.DB, replicas *Replicas, retry RetryPolicy) *CommentService {
+	return &CommentService{db: db, replicas: replicas, retry: retry, now: time.Now}
 }
 
 func (cs *CommentService) SaveComment(ctx context.Context, c *model.Comment) (string, time.Time, error) {
@@ -27,22 +32,38 @@ func (cs *CommentService) SaveComment(ctx context.Context, c *model.Comment) (st
 	comment := &model.Comment{
 		ID:        uuid.New().String(),
 		PostID:    c.PostID,
+		ParentID:  c.ParentID,
+		AuthorID:  c.AuthorID,
 		Content:   c.Content,
-		CreatedAt: time.Now(),
-	}
-	if c.ParentID != nil {
-		comment.ParentID = c.ParentID
+		CreatedAt: cs.now(),
 	}
 
 	opr := func(tx *pg.Tx) error {
 		_, err := tx.Model(comment).Insert()
 		if err != nil {
-			return fmt.Errorf("%s: failed to insert post: %w", op, err)
+			var pgErr pg.Error
+			if errors.As(err, &pgErr) && pgErr.Field('C') == foreignKeyViolation {
+				switch pgErr.Field('n') {
+				case "comments_parent_fkey":
+					return fmt.Errorf("%s: %w", op, apperr.ErrParentNotFound)
+				case "comments_post_id_fkey":
+					return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+				}
+			}
+			return fmt.Errorf("%s: failed to insert comment: %w", op, err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO post_stats (post_id, comments_count) VALUES (?, 1)
+			ON CONFLICT (post_id) DO UPDATE SET comments_count = post_stats.comments_count + 1`,
+			comment.PostID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to update comments count: %w", op, err)
 		}
 		return nil
 	}
 
-	err := retryFunc(ctx, cs.db, opr)
+	err := cs.retry.writeFunc(ctx, cs.db, opr)
 
 	if err != nil {
 		return "", time.Time{}, err
@@ -53,28 +74,113 @@ func (cs *CommentService) SaveComment(ctx context.Context, c *model.Comment) (st
 
 func (cs *CommentService) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
 	const op = "services.comments.GetComments"
+
+	filter := func(q *orm.Query) *orm.Query {
+		return q.Where("post_id = ?", postID)
+	}
+
+	comments, hasNextPage, endCursor, err := cs.getPage(ctx, first, after, filter)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, hasNextPage, endCursor, nil
+}
+
+// GetCommentsBatch возвращает первые страницы комментариев сразу для нескольких постов одним запросом
+func (cs *CommentService) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+	const op = "services.comments.GetCommentsBatch"
+	var comments []model.Comment
+
+	pages := make(map[string]*model.CommentsPage, len(postIDs))
+	for _, id := range postIDs {
+		pages[id] = &model.CommentsPage{}
+	}
+	if first == 0 || len(postIDs) == 0 {
+		return pages, nil
+	}
+
+	opr := func(tx *pg.Tx) error {
+		_, err := tx.Query(&comments, `
+			SELECT id, post_id, parent_id, author_id, content, created_at, edited_at, deleted_at
+			FROM (
+				SELECT c.*, row_number() OVER (PARTITION BY c.post_id ORDER BY c.created_at, c.id) AS rn
+				FROM comments AS c
+				WHERE c.post_id IN (?)
+			) AS page
+			WHERE rn <= ?
+			ORDER BY post_id, created_at, id`,
+			pg.In(postIDs), first+1)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range comments {
+		page := pages[c.PostID]
+		if len(page.Comments) == int(first) {
+			page.HasNextPage = true
+			continue
+		}
+		page.Comments = append(page.Comments, c)
+		page.EndCursor = c.ID
+	}
+
+	return pages, nil
+}
+
+// GetReplies возвращает страницу прямых ответов на комментарий parentID (корневые комментарии при parentID == nil)
+func (cs *CommentService) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error) {
+	const op = "services.comments.GetReplies"
+
+	filter := func(q *orm.Query) *orm.Query {
+		q = q.Where("post_id = ?", postID)
+		if parentID == nil {
+			return q.Where("parent_id IS NULL")
+		}
+		return q.Where("parent_id = ?", *parentID)
+	}
+
+	comments, hasNextPage, endCursor, err := cs.getPage(ctx, first, after, filter)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, hasNextPage, endCursor, nil
+}
+
+// getPage выбирает страницу комментариев по ключу (created_at, id).
+// Курсор after должен удовлетворять тому же фильтру, что и страница.
+func (cs *CommentService) getPage(ctx context.Context, first *int32, after *string, filter func(q *orm.Query) *orm.Query) (*[]model.Comment, bool, string, error) {
 	var comments []model.Comment
 
 	opr := func(tx *pg.Tx) error {
 		if first == nil {
-			return fmt.Errorf("%s: parameter `first` is missing", op)
+			return apperr.Validation("parameter `first` is missing")
+		} else if *first < 0 {
+			return apperr.Validation("parameter `first` must not be negative")
 		} else if *first == 0 {
 			return nil
 		}
-		query := tx.Model(&comments).
-			Where("post_id = ?", postID).
-			Order("created_at").
+		query := filter(tx.Model(&comments)).
+			Order("created_at", "id").
 			Limit(int(*first) + 1)
 
 		if after != nil && *after != "" {
 			var afterCursor model.Comment
-			err := tx.Model(&afterCursor).
+			err := filter(tx.Model(&afterCursor)).
 				Where("id = ?", *after).
 				Select()
 
 			if err != nil {
 				if errors.Is(err, pg.ErrNoRows) {
-					return fmt.Errorf("%s: invalid cursor value: %w", op, err)
+					return apperr.ErrInvalidCursor
 				}
 				return err
 			}
@@ -88,7 +194,7 @@ func (cs *CommentService) GetComments(ctx context.Context, first *int32, after *
 		return nil
 	}
 
-	err := retryFunc(ctx, cs.db, opr)
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
 	if err != nil {
 		return nil, false, "", err
 	}
@@ -105,7 +211,94 @@ func (cs *CommentService) GetComments(ctx context.Context, first *int32, after *
 	}
 
 	return &comments, hasNextPage, endCursor, nil
+}
+
+// GetThread возвращает потомков комментариев rootIDs не глубже maxDepth уровней, по запросу на уровень.
+// На каждого родителя возвращается не больше limit+1 ответов: лишний ответ означает, что у родителя есть следующая страница.
+// Следующий уровень загружается только для первых limit ответов каждого родителя.
+func (cs *CommentService) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error) {
+	const op = "services.comments.GetThread"
+	var thread []model.Comment
+
+	if len(rootIDs) == 0 || maxDepth <= 0 {
+		return thread, nil
+	}
+
+	opr := func(tx *pg.Tx) error {
+		thread = thread[:0]
+		level := rootIDs
+		for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+			var replies []model.Comment
+			_, err := tx.Query(&replies, `
+				SELECT id, post_id, parent_id, author_id, content, created_at, edited_at, deleted_at
+				FROM (
+					SELECT c.*, row_number() OVER (PARTITION BY c.parent_id ORDER BY c.created_at, c.id) AS rn
+					FROM comments AS c
+					WHERE c.post_id = ? AND c.parent_id IN (?)
+				) AS page
+				WHERE rn <= ?
+				ORDER BY parent_id, created_at, id`,
+				postID, pg.In(level), limit+1)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			thread = append(thread, replies...)
+			level = threadLevel(replies, limit)
+		}
+		return nil
+	}
+
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
+	if err != nil {
+		return nil, err
+	}
 
+	return thread, nil
+}
+
+// threadLevel возвращает идентификаторы первых limit ответов каждого родителя.
+// Ответы должны быть сгруппированы по родителю и упорядочены по времени создания.
+func threadLevel(replies []model.Comment, limit int32) []string {
+	var ids []string
+	var parentID string
+	var n int32
+	for _, c := range replies {
+		if *c.ParentID != parentID {
+			parentID, n = *c.ParentID, 0
+		}
+		if n < limit {
+			ids = append(ids, c.ID)
+		}
+		n++
+	}
+	return ids
+}
+
+// CountComments читает счетчик из post_stats, который обновляется в одной транзакции с SaveComment и DeleteComment.
+// Удаленные комментарии в счетчик не входят.
+func (cs *CommentService) CountComments(ctx context.Context, postID string) (int32, error) {
+	const op = "services.comments.CountComments"
+	var count int32
+
+	opr := func(tx *pg.Tx) error {
+		_, err := tx.QueryOne(pg.Scan(&count),
+			"SELECT comments_count FROM post_stats WHERE post_id = ?", postID)
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				count = 0
+				return nil
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
 }
 
 func (cs *CommentService) IsCommentExist(ctx context.Context, commentID string, postID string) error {
@@ -120,22 +313,135 @@ func (cs *CommentService) IsCommentExist(ctx context.Context, commentID string,
 
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				return fmt.Errorf("%s: %w", op, ErrCommentNotFound)
+				return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
 			}
 			return fmt.Errorf("%s: %w", op, err)
 		}
 
 		if comment.PostID != postID {
-			return fmt.Errorf("%s: comment from post - %s", op, *comment.ParentID)
+			return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+		}
+
+		return nil
+	}
+
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cs *CommentService) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "services.comments.GetComment"
+	var comment model.Comment
+
+	opr := func(tx *pg.Tx) error {
+		err := tx.Model(&comment).
+			Where("id = ?", id).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	err := cs.retry.readFunc(ctx, cs.db, cs.replicas, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (cs *CommentService) UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error) {
+	const op = "services.comments.UpdateComment"
+	var comment model.Comment
+
+	opr := func(tx *pg.Tx) error {
+		if err := selectForUpdate(tx, &comment, id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		comment.Content = content
+		editedAt := cs.now()
+		comment.EditedAt = &editedAt
+
+		_, err := tx.Model(&comment).
+			Column("content", "edited_at").
+			WherePK().
+			Update()
+		if err != nil {
+			return fmt.Errorf("%s: failed to update comment: %w", op, err)
+		}
+		return nil
+	}
+
+	err := cs.retry.writeFunc(ctx, cs.db, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// комментарий не удаляется из таблицы, а превращается в "надгробие" без текста,
+// чтобы ответы на него сохранили свое место в ветке
+func (cs *CommentService) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "services.comments.DeleteComment"
+	var comment model.Comment
+
+	opr := func(tx *pg.Tx) error {
+		if err := selectForUpdate(tx, &comment, id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
 
+		comment.Content = ""
+		deletedAt := cs.now()
+		comment.DeletedAt = &deletedAt
+
+		_, err := tx.Model(&comment).
+			Column("content", "deleted_at").
+			WherePK().
+			Update()
+		if err != nil {
+			return fmt.Errorf("%s: failed to delete comment: %w", op, err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE post_stats SET comments_count = comments_count - 1 WHERE post_id = ?", comment.PostID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to update post stats: %w", op, err)
+		}
 		return nil
 	}
 
-	err := retryFunc(ctx, cs.db, opr)
+	err := cs.retry.writeFunc(ctx, cs.db, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func selectForUpdate(tx *pg.Tx, comment *model.Comment, id string) error {
+	err := tx.Model(comment).
+		Where("id = ?", id).
+		For("UPDATE").
+		Select()
 	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return apperr.ErrCommentNotFound
+		}
 		return err
 	}
 
+	if comment.DeletedAt != nil {
+		return apperr.ErrCommentDeleted
+	}
 	return nil
 }
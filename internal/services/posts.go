@@ -1,6 +1,7 @@
 package services
 
 import (
+	"client-services/internal/apperr"
 	"client-services/internal/graph/model"
 	"context"
 	"errors"
@@ -12,13 +13,14 @@ import (
 )
 
 type PostService struct {
-	db *pg.DB
+	db       *pg.DB
+	replicas *Replicas
+	retry    RetryPolicy
+	now      func() time.Time
 }
 
-var ErrPostNotFound = errors.New("post not found")
-
-func NewPostService(db *pg.DB) *PostService {
-	return &PostService{db: db}
+func NewPostService(db *pg.DB, replicas *Replicas, retry RetryPolicy) *PostService {
+	return &PostService{db: db, replicas: replicas, retry: retry, now: time.Now}
 }
 
 func (ps *PostService) SavePost(ctx context.Context, p *model.Post) (string, time.Time, error) {
@@ -26,11 +28,13 @@ func (ps *PostService) SavePost(ctx context.Context, p *model.Post) (string, tim
 
 	post := &model.Post{
 		ID:              uuid.New().String(),
+		AuthorID:        p.AuthorID,
 		Title:           p.Title,
 		Content:         p.Content,
 		Comments:        p.Comments,
 		CommentsAllowed: p.CommentsAllowed,
-		CreatedAt:       time.Now(),
+		Private:         p.Private,
+		CreatedAt:       ps.now(),
 	}
 
 	opr := func(tx *pg.Tx) error {
@@ -41,7 +45,7 @@ func (ps *PostService) SavePost(ctx context.Context, p *model.Post) (string, tim
 		return nil
 	}
 
-	err := retryFunc(ctx, ps.db, opr)
+	err := ps.retry.writeFunc(ctx, ps.db, opr)
 
 	if err != nil {
 		return "", time.Time{}, err
@@ -61,14 +65,14 @@ func (ps *PostService) GetPost(ctx context.Context, id string) (*model.Post, err
 			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				return fmt.Errorf("%s: %w", op, ErrPostNotFound)
+				return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
 			}
 			return fmt.Errorf("%s: %w", op, err)
 		}
 		return nil
 	}
 
-	err := retryFunc(ctx, ps.db, opr)
+	err := ps.retry.readFunc(ctx, ps.db, ps.replicas, opr)
 
 	if err != nil {
 		return nil, err
@@ -82,17 +86,17 @@ func (ps *PostService) GetAllPosts(ctx context.Context) ([]model.Post, error) {
 	var posts []model.Post
 
 	opr := func(tx *pg.Tx) error {
-		err := tx.Model(&posts).Select()
+		err := tx.Model(&posts).Order("created_at", "id").Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				return fmt.Errorf("%s: %w", op, ErrPostNotFound)
+				return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
 			}
 			return fmt.Errorf("%s: %w", op, err)
 		}
 		return nil
 	}
 
-	err := retryFunc(ctx, ps.db, opr)
+	err := ps.retry.readFunc(ctx, ps.db, ps.replicas, opr)
 
 	if err != nil {
 		return nil, err
@@ -100,3 +104,162 @@ func (ps *PostService) GetAllPosts(ctx context.Context) ([]model.Post, error) {
 
 	return posts, nil
 }
+
+// GetPosts выбирает страницу постов по ключу (created_at, id)
+func (ps *PostService) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+	const op = "services.posts.GetPosts"
+	var posts []model.Post
+
+	opr := func(tx *pg.Tx) error {
+		if first == nil {
+			return fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+		} else if *first < 0 {
+			return fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` must not be negative"))
+		} else if *first == 0 {
+			return nil
+		}
+
+		query := tx.Model(&posts).
+			Limit(int(*first) + 1)
+		if desc {
+			query = query.Order("created_at DESC", "id DESC")
+		} else {
+			query = query.Order("created_at ASC", "id ASC")
+		}
+
+		if after != nil && *after != "" {
+			var afterCursor model.Post
+			err := tx.Model(&afterCursor).
+				Where("id = ?", *after).
+				Select()
+
+			if err != nil {
+				if errors.Is(err, pg.ErrNoRows) {
+					return fmt.Errorf("%s: %w", op, apperr.ErrInvalidCursor)
+				}
+				return err
+			}
+			if desc {
+				query = query.Where("(created_at, id) < (?, ?)", afterCursor.CreatedAt, afterCursor.ID)
+			} else {
+				query = query.Where("(created_at, id) > (?, ?)", afterCursor.CreatedAt, afterCursor.ID)
+			}
+		}
+
+		if err := query.Select(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	err := ps.retry.readFunc(ctx, ps.db, ps.replicas, opr)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	hasNextPage := false
+	if len(posts) == int(*first)+1 {
+		hasNextPage = true
+		posts = posts[:len(posts)-1]
+	}
+
+	var endCursor string
+	if len(posts) > 0 {
+		endCursor = posts[len(posts)-1].ID
+	}
+
+	return &posts, hasNextPage, endCursor, nil
+}
+
+func (ps *PostService) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	const op = "services.posts.UpdatePost"
+	var post model.Post
+
+	opr := func(tx *pg.Tx) error {
+		err := tx.Model(&post).
+			Where("id = ?", id).
+			For("UPDATE").
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if title != nil {
+			post.Title = *title
+		}
+		if content != nil {
+			post.Content = *content
+		}
+		editedAt := ps.now()
+		post.EditedAt = &editedAt
+
+		_, err = tx.Model(&post).
+			Column("title", "content", "edited_at").
+			WherePK().
+			Update()
+		if err != nil {
+			return fmt.Errorf("%s: failed to update post: %w", op, err)
+		}
+		return nil
+	}
+
+	err := ps.retry.writeFunc(ctx, ps.db, opr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (ps *PostService) DeletePost(ctx context.Context, id string) error {
+	const op = "services.posts.DeletePost"
+
+	// комментарии и счетчик удаляются каскадно внешними ключами
+	opr := func(tx *pg.Tx) error {
+		res, err := tx.Model((*model.Post)(nil)).
+			Where("id = ?", id).
+			Delete()
+		if err != nil {
+			return fmt.Errorf("%s: failed to delete post: %w", op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+		}
+		return nil
+	}
+
+	return ps.retry.writeFunc(ctx, ps.db, opr)
+}
+
+func (ps *PostService) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error) {
+	const op = "services.posts.SetCommentsAllowed"
+	var post model.Post
+
+	opr := func(tx *pg.Tx) error {
+		res, err := tx.Model(&post).
+			Set("comments_allowed = ?", allowed).
+			Where("id = ?", id).
+			Returning("*").
+			Update()
+		if err != nil {
+			return fmt.Errorf("%s: failed to update post: %w", op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+		}
+		return nil
+	}
+
+	err := ps.retry.writeFunc(ctx, ps.db, opr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Replicas распределяет запросы только на чтение между репликами по кругу.
+// Если реплика недоступна, запрос выполняется на следующей, а затем на основной базе.
+type Replicas struct {
+	dbs            []*pg.DB
+	next           atomic.Uint64
+	readYourWrites bool
+}
+
+// NewReplicas создает распределитель; readYourWrites направляет чтения на основную базу
+// после записи в рамках того же запроса (см. WithSession)
+func NewReplicas(dbs []*pg.DB, readYourWrites bool) *Replicas {
+	return &Replicas{dbs: dbs, readYourWrites: readYourWrites}
+}
+
+type sessionKey struct{}
+
+// WithSession добавляет в контекст отметку о записи, которую выставляют успешные изменения данных.
+// Последующие чтения с этим контекстом выполняются на основной базе и видят только что записанные данные.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, new(atomic.Bool))
+}
+
+func markWritten(ctx context.Context) {
+	if written, ok := ctx.Value(sessionKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+func hasWritten(ctx context.Context) bool {
+	written, ok := ctx.Value(sessionKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
+
+// pick возвращает реплики в порядке обхода для очередного запроса; пустой список - читать с основной базы
+func (r *Replicas) pick(ctx context.Context) []*pg.DB {
+	if r == nil || len(r.dbs) == 0 {
+		return nil
+	}
+	if r.readYourWrites && hasWritten(ctx) {
+		return nil
+	}
+
+	start := int(r.next.Add(1) % uint64(len(r.dbs)))
+	order := make([]*pg.DB, 0, len(r.dbs))
+	order = append(order, r.dbs[start:]...)
+	return append(order, r.dbs[:start]...)
+}
+
+// readFunc выполняет op на реплике, а если ни одна реплика недоступна - на основной базе
+func (p RetryPolicy) readFunc(ctx context.Context, primary *pg.DB, replicas *Replicas, op func(tx *pg.Tx) error) error {
+	return p.readFrom(ctx, replicas.pick(ctx), primary, func(db *pg.DB) error {
+		return db.RunInTransaction(ctx, op)
+	})
+}
+
+// readFrom делает по одной попытке на каждой реплике и переходит к следующей только при временной ошибке.
+// На основной базе запрос повторяется по политике p.
+func (p RetryPolicy) readFrom(ctx context.Context, replicas []*pg.DB, primary *pg.DB, fn func(db *pg.DB) error) error {
+	for _, db := range replicas {
+		err := fn(db)
+		if err == nil || !mayRetryRead(err) {
+			return err
+		}
+	}
+
+	return p.do(ctx, mayRetryRead, func() error {
+		return fn(primary)
+	})
+}
+
+// writeFunc выполняет изменяющую транзакцию на основной базе и отмечает запись в контексте.
+// Транзакция повторяется, только если сервер подтвердил ее откат, но не при обрыве соединения.
+func (p RetryPolicy) writeFunc(ctx context.Context, db *pg.DB, op func(tx *pg.Tx) error) error {
+	err := p.do(ctx, mayRetry, func() error {
+		return db.RunInTransaction(ctx, op)
+	})
+	if err != nil {
+		return err
+	}
+	markWritten(ctx)
+	return nil
+}
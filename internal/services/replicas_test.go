@@ -0,0 +1,84 @@
+package services
+
+import (
+	"client-services/internal/apperr"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicas_RoundRobin(t *testing.T) {
+	dbs := []*pg.DB{{}, {}, {}}
+	replicas := NewReplicas(dbs, true)
+
+	first := make(map[*pg.DB]int)
+	for i := 0; i < 6; i++ {
+		order := replicas.pick(context.Background())
+		require.Len(t, order, 3)
+		require.ElementsMatch(t, dbs, order)
+		first[order[0]]++
+	}
+
+	// каждая реплика получает одинаковую долю запросов
+	for _, db := range dbs {
+		require.Equal(t, 2, first[db])
+	}
+}
+
+func TestReplicas_ReadYourWrites(t *testing.T) {
+	dbs := []*pg.DB{{}}
+
+	ctx := WithSession(context.Background())
+	require.Len(t, NewReplicas(dbs, true).pick(ctx), 1)
+
+	markWritten(ctx)
+	require.Empty(t, NewReplicas(dbs, true).pick(ctx))
+	require.Len(t, NewReplicas(dbs, false).pick(ctx), 1)
+
+	// без реплик чтение идет на основную базу
+	var none *Replicas
+	require.Empty(t, none.pick(ctx))
+
+	// без сессии запись не отмечается
+	markWritten(context.Background())
+	require.False(t, hasWritten(context.Background()))
+}
+
+func TestRetryPolicy_ReadFrom(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	primary, replica1, replica2 := &pg.DB{}, &pg.DB{}, &pg.DB{}
+
+	// недоступные реплики пропускаются, запрос выполняется на основной базе
+	var calls []*pg.DB
+	err := policy.readFrom(context.Background(), []*pg.DB{replica1, replica2}, primary, func(db *pg.DB) error {
+		calls = append(calls, db)
+		if db != primary {
+			return io.EOF
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*pg.DB{replica1, replica2, primary}, calls)
+
+	// ошибка приложения на реплике возвращается без перехода на основную базу
+	calls = nil
+	err = policy.readFrom(context.Background(), []*pg.DB{replica1, replica2}, primary, func(db *pg.DB) error {
+		calls = append(calls, db)
+		return apperr.ErrPostNotFound
+	})
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	require.Equal(t, []*pg.DB{replica1}, calls)
+
+	// на основной базе запрос повторяется по политике
+	calls = nil
+	err = policy.readFrom(context.Background(), nil, primary, func(db *pg.DB) error {
+		calls = append(calls, db)
+		return testPgError{"40001"}
+	})
+	require.ErrorContains(t, err, "operation failed after 2 attempts")
+	require.Len(t, calls, 2)
+}
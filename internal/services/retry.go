@@ -1,50 +1,140 @@
 package services
 
 import (
+	"client-services/internal/config"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
 )
 
-const (
-	maxRetries = 5
-	retryDelay = 2 * time.Second
-)
+// RetryPolicy определяет, сколько раз и с какими паузами повторяется транзакция.
+// Пауза перед n-й повторной попыткой растет как BaseDelay * 2^(n-1), ограничена MaxDelay
+// и случайно уменьшается до половины, чтобы конкурирующие транзакции не повторялись одновременно.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// OnRetry вызывается перед каждой повторной попыткой; может быть nil
+	OnRetry func()
+}
+
+func NewRetryPolicy(cfg *config.StorageConnect) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: max(cfg.RetryMaxAttempts, 1),
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+	}
+}
+
+// retryFunc выполняет читающую транзакцию op, повторяя ее при временных ошибках и обрыве соединения
+func (p RetryPolicy) retryFunc(ctx context.Context, db *pg.DB, op func(tx *pg.Tx) error) error {
+	return p.do(ctx, mayRetryRead, func() error {
+		return db.RunInTransaction(ctx, op)
+	})
+}
 
-func retryFunc(ctx context.Context, db *pg.DB, op func(tx *pg.Tx) error) error {
+// do вызывает fn, пока она завершается ошибкой, для которой retryable возвращает true
+func (p RetryPolicy) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
 	var err error
 
-	for i := 0; i < maxRetries; i++ {
-		err = db.RunInTransaction(ctx, op)
-		if err == nil {
-			return nil
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			break
+		}
+		if p.OnRetry != nil {
+			p.OnRetry()
 		}
 
-		if !mayRetry(err) {
-			return err
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry interrupted: %w: %w", ctx.Err(), err)
+		case <-timer.C:
 		}
+	}
+
+	return fmt.Errorf("operation failed after %d attempts: %w", p.MaxAttempts, err)
+}
 
-		time.Sleep(time.Duration(i+1) * retryDelay)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+	if delay <= 0 {
+		return 0
 	}
 
-	return fmt.Errorf("operation failed after %d retries: %w", maxRetries, err)
+	half := delay / 2
+	return half + rand.N(delay-half+1)
 }
 
+// коды SQLSTATE, при которых транзакцию можно безопасно повторить
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateTooManyConnections   = "53300"
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateCannotConnectNow     = "57P03"
+	// класс 08 - ошибки соединения
+	sqlStateClassConnection = "08"
+)
+
+// mayRetry сообщает, что сервер отклонил транзакцию и ее повтор может завершиться успешно.
+// Такие ошибки гарантируют откат, поэтому их можно повторять и для изменяющих транзакций.
+// Ошибки приложения, нарушения ограничений и отмена контекста не повторяются.
 func mayRetry(err error) bool {
-	if err == nil {
-		return true
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
 
-	errMsg := err.Error()
-	if strings.Contains(errMsg, "timeout") ||
-		strings.Contains(errMsg, "post not found") ||
-		strings.Contains(errMsg, "deadlock detected") ||
-		strings.Contains(errMsg, "canceling statement due to conflict") ||
-		strings.Contains(errMsg, "could not serialize access") {
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Field('C') {
+		case sqlStateSerializationFailure,
+			sqlStateDeadlockDetected,
+			sqlStateTooManyConnections,
+			sqlStateAdminShutdown,
+			sqlStateCannotConnectNow:
+			return true
+		}
+	}
+	return false
+}
+
+// mayRetryRead дополнительно повторяет транзакцию при обрыве соединения.
+// После обрыва неизвестно, был ли выполнен COMMIT, поэтому это допустимо только для чтения:
+// повтор записи мог бы создать дубликат.
+func mayRetryRead(err error) bool {
+	if mayRetry(err) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
 	}
-	return true
+	return connectionLost(err)
+}
+
+// connectionLost сообщает, что соединение разорвано до получения ответа от сервера
+func connectionLost(err error) bool {
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Field('C'), sqlStateClassConnection)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"client-services/internal/apperr"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPgError struct {
+	code string
+}
+
+func (e testPgError) Error() string            { return "ERROR #" + e.code }
+func (e testPgError) Field(field byte) string  { return map[byte]string{'C': e.code}[field] }
+func (e testPgError) IntegrityViolation() bool { return e.code[:2] == "23" }
+
+func TestMayRetry(t *testing.T) {
+	tests := []struct {
+		tErr       error
+		tRetry     bool
+		tRetryRead bool
+	}{
+		{testPgError{"40001"}, true, true},
+		{testPgError{"40P01"}, true, true},
+		{testPgError{"57P01"}, true, true},
+		{fmt.Errorf("services.posts.SavePost: %w", testPgError{"40001"}), true, true},
+		// после обрыва соединения результат COMMIT неизвестен: повторяется только чтение
+		{testPgError{"08006"}, false, true},
+		{&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, false, true},
+		{io.EOF, false, true},
+		{fmt.Errorf("services.posts.SavePost: %w", io.ErrUnexpectedEOF), false, true},
+		{testPgError{"23505"}, false, false},
+		{testPgError{"23503"}, false, false},
+		{testPgError{"42P01"}, false, false},
+		{fmt.Errorf("services.posts.GetPost: %w", apperr.ErrPostNotFound), false, false},
+		{context.Canceled, false, false},
+		{errors.New("unexpected"), false, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.tRetry, mayRetry(tt.tErr), tt.tErr.Error())
+		require.Equal(t, tt.tRetryRead, mayRetryRead(tt.tErr), tt.tErr.Error())
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	retries := 0
+	policy := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond,
+		OnRetry: func() { retries++ }}
+
+	// временная ошибка исчезает на третьей попытке
+	calls := 0
+	err := policy.do(context.Background(), mayRetry, func() error {
+		calls++
+		if calls < 3 {
+			return testPgError{"40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, 2, retries)
+
+	// попытки исчерпаны
+	calls, retries = 0, 0
+	err = policy.do(context.Background(), mayRetry, func() error {
+		calls++
+		return testPgError{"40P01"}
+	})
+	require.ErrorContains(t, err, "operation failed after 4 attempts")
+	require.Equal(t, 4, calls)
+	require.Equal(t, 3, retries)
+
+	// нарушение ограничения не повторяется
+	calls = 0
+	err = policy.do(context.Background(), mayRetry, func() error {
+		calls++
+		return testPgError{"23505"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+
+	// обрыв соединения не повторяется для записи, но повторяется для чтения
+	calls = 0
+	err = policy.do(context.Background(), mayRetry, func() error {
+		calls++
+		return io.EOF
+	})
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	err = policy.do(context.Background(), mayRetryRead, func() error {
+		calls++
+		return io.EOF
+	})
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 4, calls)
+}
+
+func TestRetryPolicy_ContextCanceled(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := policy.do(ctx, mayRetry, func() error {
+		return testPgError{"40001"}
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	tests := []struct {
+		tAttempt int
+		tMax     time.Duration
+	}{
+		{1, 10 * time.Millisecond},
+		{2, 20 * time.Millisecond},
+		{3, 40 * time.Millisecond},
+		{4, 50 * time.Millisecond},
+		{40, 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			delay := policy.backoff(tt.tAttempt)
+			require.GreaterOrEqual(t, delay, tt.tMax/2)
+			require.LessOrEqual(t, delay, tt.tMax)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+)
+
+// UserService работает только с основной базой: вход сразу после регистрации
+// и проверка роли не должны зависеть от отставания реплик
+type UserService struct {
+	db    *pg.DB
+	retry RetryPolicy
+}
+
+// userRecord - строка таблицы users; хэш пароля не входит в GraphQL-модель
+type userRecord struct {
+	tableName struct{} `pg:"users"`
+
+	ID           string    `pg:"id,pk"`
+	Username     string    `pg:"username"`
+	Role         string    `pg:"role"`
+	PasswordHash string    `pg:"password_hash"`
+	CreatedAt    time.Time `pg:"created_at"`
+}
+
+const uniqueViolation = "23505"
+
+func NewUserService(db *pg.DB, retry RetryPolicy) *UserService {
+	return &UserService{db: db, retry: retry}
+}
+
+func (us *UserService) SaveUser(ctx context.Context, u *model.User, passwordHash string) (string, time.Time, error) {
+	const op = "services.users.SaveUser"
+
+	record := &userRecord{
+		ID:           uuid.New().String(),
+		Username:     u.Username,
+		Role:         u.Role.String(),
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+	}
+
+	opr := func(tx *pg.Tx) error {
+		_, err := tx.Model(record).Insert()
+		if err != nil {
+			var pgErr pg.Error
+			if errors.As(err, &pgErr) && pgErr.Field('C') == uniqueViolation {
+				return fmt.Errorf("%s: %w", op, apperr.ErrUserExists)
+			}
+			return fmt.Errorf("%s: failed to insert user: %w", op, err)
+		}
+		return nil
+	}
+
+	err := us.retry.writeFunc(ctx, us.db, opr)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return record.ID, record.CreatedAt, nil
+}
+
+func (us *UserService) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	const op = "services.users.GetUserByName"
+	var record userRecord
+
+	opr := func(tx *pg.Tx) error {
+		err := tx.Model(&record).
+			Where("username = ?", username).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				return fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	err := us.retry.retryFunc(ctx, us.db, opr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return record.toModel(), record.PasswordHash, nil
+}
+
+func (us *UserService) GetUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	const op = "services.users.GetUsers"
+	var records []userRecord
+
+	users := make(map[string]*model.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	opr := func(tx *pg.Tx) error {
+		err := tx.Model(&records).
+			Where("id IN (?)", pg.In(ids)).
+			Select()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	err := us.retry.retryFunc(ctx, us.db, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		users[records[i].ID] = records[i].toModel()
+	}
+
+	return users, nil
+}
+
+func (us *UserService) SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error) {
+	const op = "services.users.SetUserRole"
+	record := &userRecord{ID: id, Role: role.String()}
+
+	opr := func(tx *pg.Tx) error {
+		res, err := tx.Model(record).
+			Column("role").
+			WherePK().
+			Returning("*").
+			Update()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if res.RowsAffected() == 0 {
+			return fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+		}
+		return nil
+	}
+
+	err := us.retry.writeFunc(ctx, us.db, opr)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.toModel(), nil
+}
+
+func (r *userRecord) toModel() *model.User {
+	return &model.User{
+		ID:        r.ID,
+		Username:  r.Username,
+		Role:      model.Role(r.Role),
+		CreatedAt: r.CreatedAt,
+	}
+}
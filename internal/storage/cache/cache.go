@@ -0,0 +1,244 @@
+// Package cache - кэширующие декораторы PostInterface и CommentInterface поверх любого хранилища.
+// Кэшируются посты, список всех постов и первые страницы комментариев; записи удаляются при изменениях
+// через декораторы и по истечении TTL. Изменения, сделанные другими экземплярами сервиса, становятся видны
+// не позже чем через TTL.
+package cache
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// Stats - число попаданий и промахов кэша
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type counters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *counters) hit() {
+	c.hits.Add(1)
+}
+
+func (c *counters) miss() {
+	c.misses.Add(1)
+}
+
+func (c *counters) stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// postPages - первые страницы комментариев одного поста по значению first.
+// Карта не изменяется после записи в кэш: добавление страницы создает новую карту.
+type postPages map[int32]*model.CommentsPage
+
+type allPosts struct {
+	posts   []model.Post
+	expires time.Time
+}
+
+// Cache хранит данные, общие для декораторов постов и комментариев:
+// удаление поста очищает и кэш его комментариев.
+type Cache struct {
+	// время жизни записей; 0 - записи удаляются только при изменениях и вытеснении
+	ttl time.Duration
+
+	// mu упорядочивает запись в кэш и инвалидацию.
+	// gen увеличивается при каждой инвалидации: значение, прочитанное из хранилища до нее,
+	// в кэш не записывается, иначе запись, начатая до изменения, вернула бы устаревшие данные.
+	mu    sync.Mutex
+	gen   uint64
+	posts *expirable.LRU[string, model.Post]
+	pages *expirable.LRU[string, postPages]
+	all   *allPosts
+
+	postStats counters
+	pageStats counters
+	allStats  counters
+}
+
+// New создает кэш. Размеры и TTL должны быть положительными:
+// при нулевом значении expirable.LRU не ограничивает число записей или время их жизни.
+func New(cfg *config.StorageCache) (*Cache, error) {
+	const op = "storage.cache.New"
+
+	if cfg.Posts <= 0 || cfg.Pages <= 0 {
+		return nil, fmt.Errorf("%s: cache sizes must be positive, got posts=%d pages=%d", op, cfg.Posts, cfg.Pages)
+	}
+	if cfg.TTL <= 0 {
+		return nil, fmt.Errorf("%s: cache ttl must be positive, got %s", op, cfg.TTL)
+	}
+
+	return &Cache{
+		ttl:   cfg.TTL,
+		posts: expirable.NewLRU[string, model.Post](cfg.Posts, nil, cfg.TTL),
+		pages: expirable.NewLRU[string, postPages](cfg.Pages, nil, cfg.TTL),
+	}, nil
+}
+
+// Wrap возвращает декораторы posts и comments, использующие общий кэш
+func (c *Cache) Wrap(posts graph.PostInterface, comments graph.CommentInterface) (*Posts, *Comments) {
+	return &Posts{next: posts, cache: c}, &Comments{next: comments, cache: c}
+}
+
+// CacheStats - статистика кэша по видам записей
+type CacheStats struct {
+	Posts    Stats
+	AllPosts Stats
+	Pages    Stats
+}
+
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Posts:    c.postStats.stats(),
+		AllPosts: c.allStats.stats(),
+		Pages:    c.pageStats.stats(),
+	}
+}
+
+// generation возвращает номер поколения, который передается в store* после чтения из хранилища
+func (c *Cache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen
+}
+
+func (c *Cache) getPost(id string) (*model.Post, bool) {
+	post, ok := c.posts.Get(id)
+	if !ok {
+		c.postStats.miss()
+		return nil, false
+	}
+	c.postStats.hit()
+	return &post, true
+}
+
+func (c *Cache) storePost(gen uint64, post *model.Post) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen == gen {
+		c.posts.Add(post.ID, cachedPost(post))
+	}
+}
+
+func (c *Cache) getAllPosts() ([]model.Post, bool) {
+	c.mu.Lock()
+	all := c.all
+	c.mu.Unlock()
+
+	if all == nil || (c.ttl > 0 && time.Now().After(all.expires)) {
+		c.allStats.miss()
+		return nil, false
+	}
+	c.allStats.hit()
+	return copyPosts(all.posts), true
+}
+
+func (c *Cache) storeAllPosts(gen uint64, posts []model.Post) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen == gen {
+		c.all = &allPosts{posts: copyPosts(posts), expires: time.Now().Add(c.ttl)}
+	}
+}
+
+func (c *Cache) getPage(postID string, first int32) (*model.CommentsPage, bool) {
+	pages, ok := c.pages.Get(postID)
+	if ok {
+		if page, ok := pages[first]; ok {
+			c.pageStats.hit()
+			return copyPage(page), true
+		}
+	}
+	c.pageStats.miss()
+	return nil, false
+}
+
+func (c *Cache) storePages(gen uint64, first int32, pages map[string]*model.CommentsPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		return
+	}
+	for postID, page := range pages {
+		old, _ := c.pages.Peek(postID)
+		updated := make(postPages, len(old)+1)
+		for f, p := range old {
+			updated[f] = p
+		}
+		updated[first] = copyPage(page)
+		c.pages.Add(postID, updated)
+	}
+}
+
+// invalidatePost удаляет пост и список всех постов
+func (c *Cache) invalidatePost(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.posts.Remove(id)
+	c.all = nil
+}
+
+// invalidatePages удаляет первые страницы комментариев поста
+func (c *Cache) invalidatePages(postID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.pages.Remove(postID)
+}
+
+// invalidate запрещает запись значений, прочитанных до изменения, не зная затронутых ключей
+func (c *Cache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+}
+
+// cachedPost копирует пост без полей, которые заполняют резолверы
+func cachedPost(p *model.Post) model.Post {
+	post := *p
+	post.Author = nil
+	post.Comments = nil
+	return post
+}
+
+func copyPosts(posts []model.Post) []model.Post {
+	copied := make([]model.Post, len(posts))
+	for i := range posts {
+		copied[i] = cachedPost(&posts[i])
+	}
+	return copied
+}
+
+func copyPage(page *model.CommentsPage) *model.CommentsPage {
+	copied := &model.CommentsPage{
+		Comments:    make([]model.Comment, len(page.Comments)),
+		HasNextPage: page.HasNextPage,
+		EndCursor:   page.EndCursor,
+	}
+	for i, c := range page.Comments {
+		c.Author = nil
+		c.Replies = nil
+		copied.Comments[i] = c
+	}
+	return copied
+}
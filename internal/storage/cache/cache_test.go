@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph/mocks"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, ttl time.Duration) (*Cache, *Posts, *Comments, *mocks.MockPostInterface, *mocks.MockCommentInterface) {
+	ctrl := gomock.NewController(t)
+	postsMock := mocks.NewMockPostInterface(ctrl)
+	commentsMock := mocks.NewMockCommentInterface(ctrl)
+
+	c, err := New(&config.StorageCache{Enabled: true, Posts: 10, Pages: 10, TTL: ttl})
+	require.NoError(t, err)
+	posts, comments := c.Wrap(postsMock, commentsMock)
+	return c, posts, comments, postsMock, commentsMock
+}
+
+func TestPosts_GetPost(t *testing.T) {
+	ctx := context.Background()
+	c, posts, _, postsMock, _ := newTestCache(t, time.Minute)
+
+	postsMock.EXPECT().GetPost(ctx, "1").Return(&model.Post{ID: "1", Title: "title"}, nil).Times(1)
+
+	post, err := posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	// резолвер заполняет комментарии в возвращенном посте, кэш это не затрагивает
+	post.Comments = &model.CommentConnection{}
+	post.Title = "changed"
+
+	post, err = posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "title", post.Title)
+	require.Nil(t, post.Comments)
+	require.Equal(t, Stats{Hits: 1, Misses: 1}, c.Stats().Posts)
+
+	// изменение поста удаляет его из кэша
+	postsMock.EXPECT().UpdatePost(ctx, "1", gomock.Any(), nil).Return(&model.Post{ID: "1", Title: "new"}, nil)
+	postsMock.EXPECT().GetPost(ctx, "1").Return(&model.Post{ID: "1", Title: "new"}, nil)
+
+	_, err = posts.UpdatePost(ctx, "1", &post.Title, nil)
+	require.NoError(t, err)
+	post, err = posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "new", post.Title)
+
+	// ошибки не кэшируются
+	errStorage := errors.New("storage error")
+	postsMock.EXPECT().GetPost(ctx, "2").Return(nil, errStorage).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err = posts.GetPost(ctx, "2")
+		require.ErrorIs(t, err, errStorage)
+	}
+}
+
+func TestPosts_GetAllPosts(t *testing.T) {
+	ctx := context.Background()
+	_, posts, _, postsMock, _ := newTestCache(t, time.Minute)
+
+	postsMock.EXPECT().GetAllPosts(ctx).Return([]model.Post{{ID: "1"}}, nil).Times(1)
+	for i := 0; i < 3; i++ {
+		all, err := posts.GetAllPosts(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+	}
+
+	// новый пост сбрасывает список
+	postsMock.EXPECT().SavePost(ctx, gomock.Any()).Return("2", time.Now(), nil)
+	postsMock.EXPECT().GetAllPosts(ctx).Return([]model.Post{{ID: "1"}, {ID: "2"}}, nil)
+
+	_, _, err := posts.SavePost(ctx, &model.Post{})
+	require.NoError(t, err)
+	all, err := posts.GetAllPosts(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	_, posts, _, postsMock, _ := newTestCache(t, 20*time.Millisecond)
+
+	postsMock.EXPECT().GetPost(ctx, "1").Return(&model.Post{ID: "1"}, nil).Times(2)
+	postsMock.EXPECT().GetAllPosts(ctx).Return(nil, nil).Times(2)
+
+	_, err := posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	_, err = posts.GetAllPosts(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	_, err = posts.GetAllPosts(ctx)
+	require.NoError(t, err)
+}
+
+func TestComments_FirstPage(t *testing.T) {
+	ctx := context.Background()
+	c, posts, comments, postsMock, commentsMock := newTestCache(t, time.Minute)
+
+	first := int32(2)
+	page := []model.Comment{{ID: "c1", PostID: "1"}, {ID: "c2", PostID: "1"}}
+	commentsMock.EXPECT().GetComments(ctx, &first, nil, "1").Return(&page, true, "c2", nil).Times(1)
+
+	for i := 0; i < 2; i++ {
+		got, hasNext, endCursor, err := comments.GetComments(ctx, &first, nil, "1")
+		require.NoError(t, err)
+		require.Equal(t, page, *got)
+		require.True(t, hasNext)
+		require.Equal(t, "c2", endCursor)
+	}
+
+	// та же страница выдается пакетной загрузкой, недостающие посты запрашиваются у хранилища
+	commentsMock.EXPECT().GetCommentsBatch(ctx, first, []string{"2"}).
+		Return(map[string]*model.CommentsPage{"2": {}}, nil)
+	pages, err := comments.GetCommentsBatch(ctx, first, []string{"1", "2"})
+	require.NoError(t, err)
+	require.Equal(t, page, pages["1"].Comments)
+	require.Contains(t, pages, "2")
+
+	// другие страницы не кэшируются
+	after := "c2"
+	commentsMock.EXPECT().GetComments(ctx, &first, &after, "1").Return(&[]model.Comment{}, false, "", nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, _, _, err := comments.GetComments(ctx, &first, &after, "1")
+		require.NoError(t, err)
+	}
+	require.Equal(t, Stats{Hits: 2, Misses: 2}, c.Stats().Pages)
+
+	// новый комментарий сбрасывает страницы поста
+	commentsMock.EXPECT().SaveComment(ctx, gomock.Any()).Return("c3", time.Now(), nil)
+	commentsMock.EXPECT().GetComments(ctx, &first, nil, "1").Return(&page, true, "c2", nil)
+
+	_, _, err = comments.SaveComment(ctx, &model.Comment{PostID: "1"})
+	require.NoError(t, err)
+	_, _, _, err = comments.GetComments(ctx, &first, nil, "1")
+	require.NoError(t, err)
+
+	// изменение комментария сбрасывает страницы его поста
+	commentsMock.EXPECT().UpdateComment(ctx, "c1", "edited").Return(&model.Comment{ID: "c1", PostID: "1"}, nil)
+	commentsMock.EXPECT().GetCommentsBatch(ctx, first, []string{"1"}).
+		Return(map[string]*model.CommentsPage{"1": {Comments: page}}, nil)
+
+	_, err = comments.UpdateComment(ctx, "c1", "edited")
+	require.NoError(t, err)
+	_, err = comments.GetCommentsBatch(ctx, first, []string{"1"})
+	require.NoError(t, err)
+
+	// удаление поста сбрасывает и его комментарии
+	postsMock.EXPECT().DeletePost(ctx, "1").Return(nil)
+	commentsMock.EXPECT().GetComments(ctx, &first, nil, "1").Return(&[]model.Comment{}, false, "", nil)
+
+	require.NoError(t, posts.DeletePost(ctx, "1"))
+	got, _, _, err := comments.GetComments(ctx, &first, nil, "1")
+	require.NoError(t, err)
+	require.Empty(t, *got)
+}
+
+// значение, прочитанное из хранилища до инвалидации, не попадает в кэш
+func TestCache_StaleFill(t *testing.T) {
+	ctx := context.Background()
+	_, posts, _, postsMock, _ := newTestCache(t, time.Minute)
+
+	postsMock.EXPECT().GetPost(ctx, "1").DoAndReturn(func(ctx context.Context, id string) (*model.Post, error) {
+		// изменение завершается, пока чтение еще не записало результат в кэш
+		_, err := posts.SetCommentsAllowed(ctx, id, false)
+		require.NoError(t, err)
+		return &model.Post{ID: id, CommentsAllowed: true}, nil
+	})
+	postsMock.EXPECT().SetCommentsAllowed(ctx, "1", false).Return(&model.Post{ID: "1"}, nil)
+	postsMock.EXPECT().GetPost(ctx, "1").Return(&model.Post{ID: "1"}, nil)
+
+	post, err := posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	require.True(t, post.CommentsAllowed)
+
+	post, err = posts.GetPost(ctx, "1")
+	require.NoError(t, err)
+	require.False(t, post.CommentsAllowed)
+}
+
+func TestNew_Limits(t *testing.T) {
+	// без ограничений expirable.LRU хранил бы записи бессрочно и без предела числа
+	_, err := New(&config.StorageCache{Enabled: true})
+	require.ErrorContains(t, err, "cache sizes must be positive")
+	_, err = New(&config.StorageCache{Enabled: true, Posts: 10, Pages: 10})
+	require.ErrorContains(t, err, "cache ttl must be positive")
+	_, err = New(&config.StorageCache{Enabled: true, Posts: -1, Pages: 10, TTL: time.Minute})
+	require.Error(t, err)
+}
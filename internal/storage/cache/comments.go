@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"context"
+	"time"
+)
+
+// Comments - кэширующий декоратор CommentInterface.
+// Кэшируются только первые страницы комментариев поста: их запрашивают при каждом чтении поста.
+type Comments struct {
+	next  graph.CommentInterface
+	cache *Cache
+}
+
+var _ graph.CommentInterface = (*Comments)(nil)
+
+func (c *Comments) SaveComment(ctx context.Context, comment *model.Comment) (string, time.Time, error) {
+	defer c.cache.invalidatePages(comment.PostID)
+	return c.next.SaveComment(ctx, comment)
+}
+
+func (c *Comments) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
+	if first == nil || *first < 0 || (after != nil && *after != "") {
+		return c.next.GetComments(ctx, first, after, postID)
+	}
+
+	if page, ok := c.cache.getPage(postID, *first); ok {
+		return &page.Comments, page.HasNextPage, page.EndCursor, nil
+	}
+
+	gen := c.cache.generation()
+	comments, hasNextPage, endCursor, err := c.next.GetComments(ctx, first, after, postID)
+	if err != nil {
+		return nil, false, "", err
+	}
+	page := &model.CommentsPage{Comments: *comments, HasNextPage: hasNextPage, EndCursor: endCursor}
+	c.cache.storePages(gen, *first, map[string]*model.CommentsPage{postID: page})
+	return comments, hasNextPage, endCursor, nil
+}
+
+func (c *Comments) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+	result := make(map[string]*model.CommentsPage, len(postIDs))
+	var missing []string
+	for _, id := range postIDs {
+		if page, ok := c.cache.getPage(id, first); ok {
+			result[id] = page
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	gen := c.cache.generation()
+	pages, err := c.next.GetCommentsBatch(ctx, first, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.storePages(gen, first, pages)
+
+	for id, page := range pages {
+		result[id] = page
+	}
+	return result, nil
+}
+
+func (c *Comments) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error) {
+	return c.next.GetReplies(ctx, first, after, postID, parentID)
+}
+
+func (c *Comments) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error) {
+	return c.next.GetThread(ctx, postID, rootIDs, maxDepth, limit)
+}
+
+func (c *Comments) CountComments(ctx context.Context, postID string) (int32, error) {
+	return c.next.CountComments(ctx, postID)
+}
+
+func (c *Comments) IsCommentExist(ctx context.Context, commentID string, postID string) error {
+	return c.next.IsCommentExist(ctx, commentID, postID)
+}
+
+func (c *Comments) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	return c.next.GetComment(ctx, id)
+}
+
+func (c *Comments) UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error) {
+	comment, err := c.next.UpdateComment(ctx, id, content)
+	c.invalidate(comment)
+	return comment, err
+}
+
+func (c *Comments) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	comment, err := c.next.DeleteComment(ctx, id)
+	c.invalidate(comment)
+	return comment, err
+}
+
+// invalidate удаляет страницы поста измененного комментария.
+// Если хранилище вернуло ошибку, пост неизвестен, и запрещается только запись прочитанных ранее страниц.
+func (c *Comments) invalidate(comment *model.Comment) {
+	if comment == nil {
+		c.cache.invalidate()
+		return
+	}
+	c.cache.invalidatePages(comment.PostID)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"context"
+	"time"
+)
+
+// Posts - кэширующий декоратор PostInterface
+type Posts struct {
+	next  graph.PostInterface
+	cache *Cache
+}
+
+var _ graph.PostInterface = (*Posts)(nil)
+
+func (p *Posts) SavePost(ctx context.Context, post *model.Post) (string, time.Time, error) {
+	id, createdAt, err := p.next.SavePost(ctx, post)
+	p.cache.invalidatePost(id)
+	return id, createdAt, err
+}
+
+func (p *Posts) GetPost(ctx context.Context, id string) (*model.Post, error) {
+	if post, ok := p.cache.getPost(id); ok {
+		return post, nil
+	}
+
+	gen := p.cache.generation()
+	post, err := p.next.GetPost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.storePost(gen, post)
+	return post, nil
+}
+
+func (p *Posts) GetAllPosts(ctx context.Context) ([]model.Post, error) {
+	if posts, ok := p.cache.getAllPosts(); ok {
+		return posts, nil
+	}
+
+	gen := p.cache.generation()
+	posts, err := p.next.GetAllPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.storeAllPosts(gen, posts)
+	return posts, nil
+}
+
+// GetPosts не кэшируется: страницы по курсору редко запрашиваются повторно
+func (p *Posts) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+	return p.next.GetPosts(ctx, first, after, desc)
+}
+
+func (p *Posts) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	defer p.cache.invalidatePost(id)
+	return p.next.UpdatePost(ctx, id, title, content)
+}
+
+func (p *Posts) DeletePost(ctx context.Context, id string) error {
+	defer p.cache.invalidatePages(id)
+	defer p.cache.invalidatePost(id)
+	return p.next.DeletePost(ctx, id)
+}
+
+func (p *Posts) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error) {
+	defer p.cache.invalidatePost(id)
+	return p.next.SetCommentsAllowed(ctx, id, allowed)
+}
@@ -1,10 +1,10 @@
 package in_memory
 
 import (
+	"client-services/internal/apperr"
 	"client-services/internal/graph/model"
 	"context"
 	"fmt"
-	"sort"
 	"sync"
 	"time"
 
@@ -12,8 +12,15 @@ import (
 )
 
 type CommentStorage struct {
+	posts    map[string]*model.Post
 	comments map[string]*model.Comment
+	byPost   map[string]*createdOrder
+	children map[threadKey]*createdOrder
+	deleted  map[string]int
+	now      func() time.Time
+	wal      *wal
 	mu       *sync.RWMutex
+	postsMu  *sync.RWMutex
 }
 
 func (s *InMemStorage) NewCommentStorage() *CommentStorage {
@@ -21,8 +28,15 @@ func (s *InMemStorage) NewCommentStorage() *CommentStorage {
 	_ = op
 
 	cs := &CommentStorage{
+		posts:    s.posts,
 		comments: s.comments,
-		mu:       &s.mu,
+		byPost:   s.byPost,
+		children: s.children,
+		deleted:  s.deleted,
+		now:      s.now,
+		wal:      s.wal,
+		mu:       &s.commentsMu,
+		postsMu:  &s.postsMu,
 	}
 
 	return cs
@@ -30,80 +44,133 @@ func (s *InMemStorage) NewCommentStorage() *CommentStorage {
 
 func (cs *CommentStorage) SaveComment(ctx context.Context, c *model.Comment) (string, time.Time, error) {
 	const op = "storage.in-memory.SaveComment"
-	_ = op
 
+	// блокировка постов не дает удалить пост, пока к нему добавляется комментарий
+	cs.postsMu.RLock()
+	defer cs.postsMu.RUnlock()
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
+	// те же проверки, что и внешние ключи в PostgreSQL
+	if _, ok := cs.posts[c.PostID]; !ok {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+	}
+	if c.ParentID != nil {
+		parent, ok := cs.comments[*c.ParentID]
+		if !ok || parent.PostID != c.PostID {
+			return "", time.Time{}, fmt.Errorf("%s: %w", op, apperr.ErrParentNotFound)
+		}
+	}
+
 	comment := &model.Comment{
 		ID:        uuid.New().String(),
 		PostID:    c.PostID,
 		ParentID:  c.ParentID,
+		AuthorID:  c.AuthorID,
 		Content:   c.Content,
-		CreatedAt: time.Now(),
+		CreatedAt: cs.now(),
 	}
 
-	cs.comments[comment.ID] = comment
+	if err := cs.wal.append(walRecord{Op: opPutComment, Comment: comment}); err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	cs.putComment(comment)
 
 	return comment.ID, comment.CreatedAt, nil
 }
 
-func (cs *CommentStorage) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
-	const op = "storage.in-memory.GetComment"
-
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
-
-	var comments []model.Comment
-
-	if first == nil {
-		return nil, false, "", fmt.Errorf("%s: parameter `first` is missing", op)
-	} else if *first == 0 {
-		return &[]model.Comment{}, false, "", nil
+// putComment добавляет комментарий в индексы или заменяет сохраненную версию; вызывается под блокировкой записи
+func (cs *CommentStorage) putComment(comment *model.Comment) {
+	old, ok := cs.comments[comment.ID]
+	if !ok {
+		key := createdKey{createdAt: comment.CreatedAt, id: comment.ID}
+		indexInsert(cs.byPost, comment.PostID, key)
+		indexInsert(cs.children, newThreadKey(comment.PostID, comment.ParentID), key)
 	}
+	if comment.DeletedAt != nil && (!ok || old.DeletedAt == nil) {
+		cs.deleted[comment.PostID]++
+	}
+	cs.comments[comment.ID] = comment
+}
 
-	for _, c := range cs.comments {
-		if c.PostID == postID {
-			comments = append(comments, *c)
-		}
+func indexInsert[K comparable](index map[K]*createdOrder, k K, key createdKey) {
+	order, ok := index[k]
+	if !ok {
+		order = &createdOrder{}
+		index[k] = order
 	}
+	order.insert(key)
+}
 
-	sort.Slice(comments, func(i, j int) bool {
-		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
-	})
+// page возвращает до first комментариев из order, следующих за курсором after.
+// Курсор должен принадлежать order: это проверяет inOrder.
+func (cs *CommentStorage) page(order *createdOrder, first int32, after *string, inOrder func(c *model.Comment) bool) ([]model.Comment, bool, string, error) {
+	keys := order.keysOrNil()
 
-	startIndex := 0
 	if after != nil && *after != "" {
-		isFound := false
-		for i, c := range comments {
-			if c.ID == *after {
-				startIndex = i + 1
-				isFound = true
-				break
-			}
+		cursor, ok := cs.comments[*after]
+		if !ok || !inOrder(cursor) {
+			return nil, false, "", apperr.ErrInvalidCursor
 		}
-		if !isFound {
-			return nil, false, "", fmt.Errorf("%s: invalid cursor value", op)
+		keys, ok = order.after(createdKey{createdAt: cursor.CreatedAt, id: cursor.ID})
+		if !ok {
+			return nil, false, "", apperr.ErrInvalidCursor
 		}
 	}
 
-	endIndex := startIndex + int(*first)
-	if endIndex >= len(comments) {
-		endIndex = len(comments)
+	hasNextPage := len(keys) > int(first)
+	if hasNextPage {
+		keys = keys[:first]
 	}
 
-	pageComments := comments[startIndex:endIndex]
+	pageComments := make([]model.Comment, 0, len(keys))
+	for _, key := range keys {
+		pageComments = append(pageComments, *cs.comments[key.id])
+	}
 
 	var endCursor string
 	if len(pageComments) > 0 {
 		endCursor = pageComments[len(pageComments)-1].ID
 	}
 
-	hasNextPage := endIndex < len(comments)
+	return pageComments, hasNextPage, endCursor, nil
+}
+
+func (cs *CommentStorage) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
+	const op = "storage.in-memory.GetComment"
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if first == nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` must not be negative"))
+	} else if *first == 0 {
+		return &[]model.Comment{}, false, "", nil
+	}
+
+	pageComments, hasNextPage, endCursor, err := cs.page(cs.byPost[postID], *first, after, func(c *model.Comment) bool {
+		return c.PostID == postID
+	})
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
 
 	return &pageComments, hasNextPage, endCursor, nil
 }
 
+// CountComments возвращает число комментариев поста без удаленных
+func (cs *CommentStorage) CountComments(ctx context.Context, postID string) (int32, error) {
+	const op = "storage.in-memory.CountComments"
+	_ = op
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return int32(cs.byPost[postID].len() - cs.deleted[postID]), nil
+}
+
 func (cs *CommentStorage) IsCommentExist(ctx context.Context, commentID string, postID string) error {
 	const op = "storage.in-memory.IsCommentExist"
 
@@ -112,11 +179,162 @@ func (cs *CommentStorage) IsCommentExist(ctx context.Context, commentID string,
 
 	comment, ok := cs.comments[commentID]
 	if !ok {
-		return fmt.Errorf("%s: comment not found", op)
+		return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
 	}
 	if comment.PostID != postID {
-		return fmt.Errorf("%s: comment from post - %s", op, comment.PostID)
+		return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
 	}
 
 	return nil
 }
+
+func (cs *CommentStorage) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "storage.in-memory.GetComment"
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	comment, ok := cs.comments[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+	}
+
+	c := *comment
+	return &c, nil
+}
+
+func (cs *CommentStorage) UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error) {
+	const op = "storage.in-memory.UpdateComment"
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	comment, ok := cs.comments[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+	}
+	if comment.DeletedAt != nil {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrCommentDeleted)
+	}
+
+	updated := *comment
+	updated.Content = content
+	editedAt := cs.now()
+	updated.EditedAt = &editedAt
+
+	if err := cs.wal.append(walRecord{Op: opPutComment, Comment: &updated}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	cs.putComment(&updated)
+
+	c := updated
+	return &c, nil
+}
+
+// комментарий не удаляется из хранилища, а заменяется "надгробием" без текста,
+// чтобы ответы на него сохранили свое место в ветке
+func (cs *CommentStorage) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "storage.in-memory.DeleteComment"
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	comment, ok := cs.comments[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+	}
+	if comment.DeletedAt != nil {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrCommentDeleted)
+	}
+
+	deleted := *comment
+	deleted.Content = ""
+	deletedAt := cs.now()
+	deleted.DeletedAt = &deletedAt
+
+	if err := cs.wal.append(walRecord{Op: opPutComment, Comment: &deleted}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	cs.putComment(&deleted)
+
+	c := deleted
+	return &c, nil
+}
+
+// GetCommentsBatch возвращает первые страницы комментариев сразу для нескольких постов за один захват блокировки
+func (cs *CommentStorage) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+	const op = "storage.in-memory.GetCommentsBatch"
+	_ = op
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	pages := make(map[string]*model.CommentsPage, len(postIDs))
+	for _, id := range postIDs {
+		page := &model.CommentsPage{}
+		if first > 0 {
+			page.Comments, page.HasNextPage, page.EndCursor, _ = cs.page(cs.byPost[id], first, nil, nil)
+		}
+		pages[id] = page
+	}
+
+	return pages, nil
+}
+
+// GetReplies возвращает страницу прямых ответов на комментарий parentID (корневые комментарии при parentID == nil)
+func (cs *CommentStorage) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error) {
+	const op = "storage.in-memory.GetReplies"
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if first == nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` must not be negative"))
+	} else if *first == 0 {
+		return &[]model.Comment{}, false, "", nil
+	}
+
+	key := newThreadKey(postID, parentID)
+	pageComments, hasNextPage, endCursor, err := cs.page(cs.children[key], *first, after, func(c *model.Comment) bool {
+		return newThreadKey(c.PostID, c.ParentID) == key
+	})
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &pageComments, hasNextPage, endCursor, nil
+}
+
+// GetThread возвращает потомков комментариев rootIDs не глубже maxDepth уровней.
+// На каждого родителя возвращается не больше limit+1 ответов: лишний ответ означает, что у родителя есть следующая страница.
+// Следующий уровень загружается только для первых limit ответов каждого родителя.
+func (cs *CommentStorage) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error) {
+	const op = "storage.in-memory.GetThread"
+	_ = op
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var thread []model.Comment
+
+	level := rootIDs
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, parentID := range level {
+			for i, key := range cs.children[threadKey{postID: postID, parentID: parentID}].keysOrNil() {
+				if i > int(limit) {
+					break
+				}
+				thread = append(thread, *cs.comments[key.id])
+				if i < int(limit) {
+					next = append(next, key.id)
+				}
+			}
+		}
+		level = next
+	}
+
+	return thread, nil
+}
@@ -0,0 +1,116 @@
+package in_memory
+
+import (
+	"client-services/internal/graph/model"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// benchStorage заполняет хранилище posts постами по perPost комментариев;
+// половина комментариев - ответы на первый комментарий поста
+func benchStorage(b *testing.B, posts int, perPost int) (*CommentStorage, []string, [][]string) {
+	b.Helper()
+	ctx := context.Background()
+
+	s := NewStorage()
+	ps, cs := s.NewPostStorage(), s.NewCommentStorage()
+
+	postIDs := make([]string, 0, posts)
+	commentIDs := make([][]string, 0, posts)
+	for i := 0; i < posts; i++ {
+		postID, _, err := ps.SavePost(ctx, &model.Post{Title: "title", Content: "content", CommentsAllowed: true})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		ids := make([]string, 0, perPost)
+		for j := 0; j < perPost; j++ {
+			comment := &model.Comment{PostID: postID, Content: "comment"}
+			if j%2 == 1 {
+				comment.ParentID = &ids[0]
+			}
+			id, _, err := cs.SaveComment(ctx, comment)
+			if err != nil {
+				b.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+
+		postIDs = append(postIDs, postID)
+		commentIDs = append(commentIDs, ids)
+	}
+
+	return cs, postIDs, commentIDs
+}
+
+func BenchmarkGetComments(b *testing.B) {
+	for _, size := range []struct{ posts, perPost int }{{10, 100}, {100, 100}, {100, 1000}} {
+		b.Run(fmt.Sprintf("posts=%d/comments=%d", size.posts, size.perPost), func(b *testing.B) {
+			cs, postIDs, commentIDs := benchStorage(b, size.posts, size.perPost)
+			ctx := context.Background()
+			first := int32(20)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p := i % len(postIDs)
+				// страница из середины списка комментариев поста
+				after := commentIDs[p][size.perPost/2]
+				if _, _, _, err := cs.GetComments(ctx, &first, &after, postIDs[p]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetReplies(b *testing.B) {
+	cs, postIDs, commentIDs := benchStorage(b, 100, 1000)
+	ctx := context.Background()
+	first := int32(20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := i % len(postIDs)
+		parentID := commentIDs[p][0]
+		after := commentIDs[p][len(commentIDs[p])/2+1]
+		if _, _, _, err := cs.GetReplies(ctx, &first, &after, postIDs[p], &parentID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetCommentsBatch(b *testing.B) {
+	cs, postIDs, _ := benchStorage(b, 100, 1000)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cs.GetCommentsBatch(ctx, 10, postIDs[:20]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSaveComment_Parallel(b *testing.B) {
+	cs, postIDs, _ := benchStorage(b, 100, 10)
+	ctx := context.Background()
+	first := int32(20)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			postID := postIDs[i%len(postIDs)]
+			// чтения и записи комментариев вперемешку
+			if i%4 == 0 {
+				if _, _, err := cs.SaveComment(ctx, &model.Comment{PostID: postID, Content: "comment"}); err != nil {
+					b.Fatal(err)
+				}
+			} else if _, _, _, err := cs.GetComments(ctx, &first, nil, postID); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package in_memory
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentStorage_Indexes(t *testing.T) {
+	ctx := context.Background()
+	s := NewStorage()
+	ps, cs := s.NewPostStorage(), s.NewCommentStorage()
+
+	postID, _, err := ps.SavePost(ctx, &model.Post{Title: "title", Content: "content"})
+	require.NoError(t, err)
+	otherID, _, err := ps.SavePost(ctx, &model.Post{Title: "other", Content: "content"})
+	require.NoError(t, err)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, _, err := cs.SaveComment(ctx, &model.Comment{PostID: postID, Content: "comment"})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	replyID, _, err := cs.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: &ids[0], Content: "reply"})
+	require.NoError(t, err)
+	otherCommentID, _, err := cs.SaveComment(ctx, &model.Comment{PostID: otherID, Content: "comment"})
+	require.NoError(t, err)
+
+	// страницы комментариев поста в порядке создания, включая ответы
+	first := int32(2)
+	page, hasNext, endCursor, err := cs.GetComments(ctx, &first, &ids[1], postID)
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, []string{ids[2], ids[3]}, commentIDs(*page))
+	require.Equal(t, ids[3], endCursor)
+
+	first = 10
+	page, hasNext, _, err = cs.GetComments(ctx, &first, &ids[3], postID)
+	require.NoError(t, err)
+	require.False(t, hasNext)
+	require.Equal(t, []string{ids[4], replyID}, commentIDs(*page))
+
+	// курсор из другого поста или другой ветки недействителен
+	_, _, _, err = cs.GetComments(ctx, &first, &otherCommentID, postID)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+	_, _, _, err = cs.GetReplies(ctx, &first, &replyID, postID, nil)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+
+	page, _, _, err = cs.GetReplies(ctx, &first, nil, postID, &ids[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{replyID}, commentIDs(*page))
+
+	count, err := cs.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), count)
+
+	// удаление поста очищает его индексы и не затрагивает другие посты
+	require.NoError(t, ps.DeletePost(ctx, postID))
+	count, err = cs.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	_, err = cs.GetComment(ctx, replyID)
+	require.ErrorIs(t, err, apperr.ErrCommentNotFound)
+	require.Len(t, s.children, 1)
+
+	pages, err := cs.GetCommentsBatch(ctx, 10, []string{postID, otherID})
+	require.NoError(t, err)
+	require.Empty(t, pages[postID].Comments)
+	require.Equal(t, []string{otherCommentID}, commentIDs(pages[otherID].Comments))
+}
+
+func commentIDs(comments []model.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
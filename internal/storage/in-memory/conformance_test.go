@@ -0,0 +1,30 @@
+package in_memory
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage/cache"
+	"client-services/internal/storage/storagetest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clock *storagetest.Clock) storagetest.Backend {
+		s := NewStorage()
+		s.now = clock.Now
+		return storagetest.Backend{Posts: s.NewPostStorage(), Comments: s.NewCommentStorage()}
+	})
+}
+
+func TestConformance_Cached(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clock *storagetest.Clock) storagetest.Backend {
+		s := NewStorage()
+		s.now = clock.Now
+		c, err := cache.New(&config.StorageCache{Posts: 100, Pages: 100, TTL: time.Minute})
+		require.NoError(t, err)
+		posts, comments := c.Wrap(s.NewPostStorage(), s.NewCommentStorage())
+		return storagetest.Backend{Posts: posts, Comments: comments}
+	})
+}
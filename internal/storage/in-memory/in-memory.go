@@ -2,14 +2,53 @@ package in_memory
 
 import (
 	"client-services/internal/graph/model"
+	"errors"
+	"log/slog"
 	"sync"
+	"time"
 )
 
+// InMemStorage хранит посты, комментарии и пользователей под отдельными блокировками.
+// Если нужны несколько блокировок, они захватываются в порядке postsMu, commentsMu, usersMu.
 type InMemStorage struct {
-	posts    map[string]*model.Post
+	posts   map[string]*model.Post
+	order   *createdOrder
+	postsMu sync.RWMutex
+
 	comments map[string]*model.Comment
+	// комментарии поста в порядке создания, включая удаленные
+	byPost map[string]*createdOrder
+	// индекс ответов: комментарии в порядке создания по родителю
+	children map[threadKey]*createdOrder
+	// число удаленных комментариев поста: они остаются в byPost, но не входят в CountComments
+	deleted    map[string]int
+	commentsMu sync.RWMutex
+
+	users     map[string]*userRecord
+	usernames map[string]string
+	usersMu   sync.RWMutex
 
-	mu sync.RWMutex
+	// источник времени создания и изменения постов и комментариев; подменяется в тестах
+	now func() time.Time
+
+	// журнал изменений; nil, если хранилище не сохраняется на диск
+	wal     *wal
+	log     *slog.Logger
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// threadKey - ключ индекса ответов; у корневых комментариев parentID пустой
+type threadKey struct {
+	postID   string
+	parentID string
+}
+
+func newThreadKey(postID string, parentID *string) threadKey {
+	if parentID == nil {
+		return threadKey{postID: postID}
+	}
+	return threadKey{postID: postID, parentID: *parentID}
 }
 
 func NewStorage() *InMemStorage {
@@ -18,13 +57,30 @@ func NewStorage() *InMemStorage {
 
 	s := &InMemStorage{
 		posts:    make(map[string]*model.Post),
+		order:    &createdOrder{},
 		comments: make(map[string]*model.Comment),
+		byPost:   make(map[string]*createdOrder),
+		children: make(map[threadKey]*createdOrder),
+		deleted:  make(map[string]int),
+
+		users:     make(map[string]*userRecord),
+		usernames: make(map[string]string),
+
+		now: time.Now,
 	}
 
 	return s
 }
 
-// метод-пустышка для совместимости интерфейсов
+// CloseDB останавливает фоновые fsync и снимки, сохраняет итоговый снимок и закрывает журнал.
+// Для хранилища без сохранения на диск ничего не делает.
 func (s *InMemStorage) CloseDB() error {
-	return nil
+	if s.wal == nil {
+		return nil
+	}
+
+	close(s.stop)
+	<-s.stopped
+
+	return errors.Join(s.takeSnapshot(), s.wal.close())
 }
@@ -0,0 +1,72 @@
+package in_memory
+
+import (
+	"sort"
+	"time"
+)
+
+// createdOrder - ID, упорядоченные по (createdAt, id), для постраничной выдачи без сортировки на каждый запрос.
+// Используется для списка постов, комментариев поста и ответов на комментарий.
+type createdOrder struct {
+	keys []createdKey
+}
+
+type createdKey struct {
+	createdAt time.Time
+	id        string
+}
+
+func (k createdKey) less(other createdKey) bool {
+	if k.createdAt.Equal(other.createdAt) {
+		return k.id < other.id
+	}
+	return k.createdAt.Before(other.createdAt)
+}
+
+// search возвращает позицию первого ключа, не меньшего key
+func (o *createdOrder) search(key createdKey) int {
+	return sort.Search(len(o.keys), func(i int) bool {
+		return !o.keys[i].less(key)
+	})
+}
+
+func (o *createdOrder) insert(key createdKey) {
+	// новые объекты обычно создаются позже всех имеющихся
+	if n := len(o.keys); n == 0 || o.keys[n-1].less(key) {
+		o.keys = append(o.keys, key)
+		return
+	}
+
+	i := o.search(key)
+	o.keys = append(o.keys, createdKey{})
+	copy(o.keys[i+1:], o.keys[i:])
+	o.keys[i] = key
+}
+
+func (o *createdOrder) remove(key createdKey) {
+	i := o.search(key)
+	if i < len(o.keys) && o.keys[i] == key {
+		o.keys = append(o.keys[:i], o.keys[i+1:]...)
+	}
+}
+
+// after возвращает ключи, следующие за key; ok == false, если key нет в списке
+func (o *createdOrder) after(key createdKey) ([]createdKey, bool) {
+	i := o.search(key)
+	if i == len(o.keys) || o.keys[i] != key {
+		return nil, false
+	}
+	return o.keys[i+1:], true
+}
+
+// keysOrNil и len допускают nil: у поста или комментария без ответов нет списка в индексе
+func (o *createdOrder) keysOrNil() []createdKey {
+	if o == nil {
+		return nil
+	}
+	return o.keys
+}
+
+func (o *createdOrder) len() int {
+	return len(o.keysOrNil())
+}
@@ -0,0 +1,160 @@
+package in_memory
+
+import (
+	"client-services/internal/config"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Open создает хранилище, которое сохраняет изменения в журнал в каталоге cfg.Dir и периодически делает снимки.
+// При старте состояние восстанавливается из последнего снимка и журнала. Без каталога хранилище работает только в памяти.
+func Open(cfg *config.InMemory, log *slog.Logger) (*InMemStorage, error) {
+	const op = "storage.in-memory.Open"
+
+	s := NewStorage()
+	if cfg == nil || cfg.Dir == "" {
+		return s, nil
+	}
+
+	switch cfg.Fsync {
+	case FsyncAlways, FsyncEverySec, FsyncNever:
+	default:
+		return nil, fmt.Errorf("%s: unknown fsync policy %q", op, cfg.Fsync)
+	}
+	// без снимков журнал рос бы неограниченно
+	if cfg.SnapshotInterval <= 0 {
+		return nil, fmt.Errorf("%s: snapshot interval must be positive, got %s", op, cfg.SnapshotInterval)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seq, err := s.restore(cfg.Dir, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to restore: %w", op, err)
+	}
+
+	w := &wal{dir: cfg.Dir, fsync: cfg.Fsync, seq: seq}
+	// записи после перезапуска пишутся в новый сегмент, чтобы не дописывать их за оборванной строкой
+	if _, err := w.rotate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := syncDir(cfg.Dir); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.wal = w
+	s.log = log
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go s.persist(cfg.Fsync, cfg.SnapshotInterval)
+
+	log.Info("in-memory storage restored",
+		slog.String("dir", cfg.Dir),
+		slog.Uint64("seq", seq),
+		slog.Int("posts", len(s.posts)),
+		slog.Int("comments", len(s.comments)),
+	)
+
+	return s, nil
+}
+
+// restore применяет снимок и записи журнала новее него; возвращает номер последней примененной записи
+func (s *InMemStorage) restore(dir string, log *slog.Logger) (uint64, error) {
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	posts, comments, users := s.NewPostStorage(), s.NewCommentStorage(), s.NewUserStorage()
+	for i := range snap.Posts {
+		posts.putPost(&snap.Posts[i])
+	}
+	for i := range snap.Comments {
+		comments.putComment(&snap.Comments[i])
+	}
+	for _, u := range snap.Users {
+		users.putUser(&userRecord{user: u.User, passwordHash: u.PasswordHash})
+	}
+
+	seq := snap.Seq
+	apply := func(rec walRecord) error {
+		// запись уже вошла в снимок: снимок сохранен, а старые сегменты еще не удалены
+		if rec.Seq <= seq {
+			return nil
+		}
+		if rec.Seq != seq+1 {
+			return fmt.Errorf("wal record %d is missing", seq+1)
+		}
+
+		switch {
+		case rec.Op == opPutPost && rec.Post != nil:
+			posts.putPost(rec.Post)
+		case rec.Op == opDeletePost && rec.PostID != "":
+			posts.deletePost(rec.PostID)
+		case rec.Op == opPutComment && rec.Comment != nil:
+			comments.putComment(rec.Comment)
+		case rec.Op == opPutUser && rec.User != nil:
+			users.putUser(&userRecord{user: rec.User.User, passwordHash: rec.User.PasswordHash})
+		default:
+			return fmt.Errorf("invalid wal record %d with operation %q", rec.Seq, rec.Op)
+		}
+
+		seq = rec.Seq
+		return nil
+	}
+
+	files, err := segments(dir)
+	if err != nil {
+		return 0, err
+	}
+	for i, file := range files {
+		last := i == len(files)-1
+		err := readSegment(file, last, apply)
+		if errors.Is(err, errTornRecord) {
+			log.Warn("incomplete wal record removed", slog.String("file", file))
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return seq, nil
+}
+
+// persist сбрасывает журнал на диск по политике fsync и делает снимки раз в interval
+func (s *InMemStorage) persist(fsync string, interval time.Duration) {
+	defer close(s.stopped)
+
+	var syncC, snapshotC <-chan time.Time
+	if fsync == FsyncEverySec {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		syncC = ticker.C
+	}
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		snapshotC = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-syncC:
+			if err := s.wal.sync(); err != nil {
+				s.log.Error("failed to sync wal", slog.String("error", err.Error()))
+			}
+		case <-snapshotC:
+			if err := s.takeSnapshot(); err != nil {
+				s.log.Error("failed to take snapshot", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
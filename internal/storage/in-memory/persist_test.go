@@ -0,0 +1,232 @@
+package in_memory
+
+import (
+	"bytes"
+	"client-services/internal/apperr"
+	"client-services/internal/config"
+	"client-services/internal/graph/model"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func openTest(t *testing.T, dir string) *InMemStorage {
+	t.Helper()
+
+	s, err := Open(&config.InMemory{Dir: dir, Fsync: FsyncAlways, SnapshotInterval: time.Hour}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return s
+}
+
+// crash останавливает фоновые задачи и закрывает журнал без итогового снимка
+func crash(t *testing.T, s *InMemStorage) {
+	t.Helper()
+
+	close(s.stop)
+	<-s.stopped
+	require.NoError(t, s.wal.close())
+}
+
+func TestOpen_ReplayWAL(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openTest(t, dir)
+	posts, comments, users := s.NewPostStorage(), s.NewCommentStorage(), s.NewUserStorage()
+
+	postID, _, err := posts.SavePost(ctx, &model.Post{Title: "title", Content: "content", CommentsAllowed: true})
+	require.NoError(t, err)
+	deletedPostID, _, err := posts.SavePost(ctx, &model.Post{Title: "deleted", Content: "content"})
+	require.NoError(t, err)
+
+	rootID, _, err := comments.SaveComment(ctx, &model.Comment{PostID: postID, Content: "root"})
+	require.NoError(t, err)
+	replyID, _, err := comments.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: &rootID, Content: "reply"})
+	require.NoError(t, err)
+	_, _, err = comments.SaveComment(ctx, &model.Comment{PostID: deletedPostID, Content: "gone"})
+	require.NoError(t, err)
+
+	newTitle := "new title"
+	_, err = posts.UpdatePost(ctx, postID, &newTitle, nil)
+	require.NoError(t, err)
+	_, err = comments.DeleteComment(ctx, rootID)
+	require.NoError(t, err)
+	require.NoError(t, posts.DeletePost(ctx, deletedPostID))
+
+	userID, _, err := users.SaveUser(ctx, &model.User{Username: "user", Role: model.RoleUser}, "hash")
+	require.NoError(t, err)
+	_, err = users.SetUserRole(ctx, userID, model.RoleModerator)
+	require.NoError(t, err)
+
+	crash(t, s)
+
+	restored := openTest(t, dir)
+	defer restored.CloseDB()
+	posts, comments, users = restored.NewPostStorage(), restored.NewCommentStorage(), restored.NewUserStorage()
+
+	post, err := posts.GetPost(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, "new title", post.Title)
+	require.NotNil(t, post.EditedAt)
+
+	_, err = posts.GetPost(ctx, deletedPostID)
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+
+	root, err := comments.GetComment(ctx, rootID)
+	require.NoError(t, err)
+	require.NotNil(t, root.DeletedAt)
+
+	first := int32(10)
+	replies, _, _, err := comments.GetReplies(ctx, &first, nil, postID, &rootID)
+	require.NoError(t, err)
+	require.Len(t, *replies, 1)
+	require.Equal(t, replyID, (*replies)[0].ID)
+
+	// удаленный корневой комментарий не входит в восстановленный счетчик
+	count, err := comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), count)
+
+	user, hash, err := users.GetUserByName(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, model.RoleModerator, user.Role)
+	require.Equal(t, "hash", hash)
+}
+
+func TestOpen_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openTest(t, dir)
+	posts := s.NewPostStorage()
+
+	beforeID, _, err := posts.SavePost(ctx, &model.Post{Title: "before", Content: "content"})
+	require.NoError(t, err)
+	require.NoError(t, s.takeSnapshot())
+	afterID, _, err := posts.SavePost(ctx, &model.Post{Title: "after", Content: "content"})
+	require.NoError(t, err)
+
+	// в журнале остается только сегмент, начатый при снимке
+	files, err := segments(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	crash(t, s)
+
+	restored := openTest(t, dir)
+	all, err := restored.NewPostStorage().GetAllPosts(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Equal(t, beforeID, all[0].ID)
+	require.Equal(t, afterID, all[1].ID)
+
+	// при закрытии сохраняется итоговый снимок
+	require.NoError(t, restored.CloseDB())
+	snap, err := readSnapshot(dir)
+	require.NoError(t, err)
+	require.Len(t, snap.Posts, 2)
+}
+
+func TestOpen_TornRecord(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openTest(t, dir)
+	postID, _, err := s.NewPostStorage().SavePost(ctx, &model.Post{Title: "title", Content: "content"})
+	require.NoError(t, err)
+	crash(t, s)
+
+	// запись, оборванная при аварийном завершении
+	files, err := segments(dir)
+	require.NoError(t, err)
+	file, err := os.OpenFile(files[len(files)-1], os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"op":"put_po`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	restored := openTest(t, dir)
+	_, err = restored.NewPostStorage().GetPost(ctx, postID)
+	require.NoError(t, err)
+
+	secondID, _, err := restored.NewPostStorage().SavePost(ctx, &model.Post{Title: "second", Content: "content"})
+	require.NoError(t, err)
+	crash(t, restored)
+
+	restored = openTest(t, dir)
+	defer restored.CloseDB()
+	_, err = restored.NewPostStorage().GetPost(ctx, secondID)
+	require.NoError(t, err)
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := Open(&config.InMemory{Dir: t.TempDir(), Fsync: "sometimes", SnapshotInterval: time.Hour}, log)
+	require.ErrorContains(t, err, "unknown fsync policy")
+	_, err = Open(&config.InMemory{Dir: t.TempDir(), Fsync: FsyncEverySec}, log)
+	require.ErrorContains(t, err, "snapshot interval must be positive")
+
+	// повреждение в середине журнала не пропускается
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, segmentName(1)), []byte("{broken\n{}\n"), 0o600))
+	_, err = Open(&config.InMemory{Dir: dir, Fsync: FsyncNever, SnapshotInterval: time.Hour}, log)
+	require.ErrorContains(t, err, "failed to restore")
+
+	// без каталога хранилище не пишет на диск
+	s, err := Open(&config.InMemory{}, log)
+	require.NoError(t, err)
+	require.Nil(t, s.wal)
+	require.NoError(t, s.CloseDB())
+}
+
+// memFile - сегмент журнала в памяти с подменяемыми ошибками сброса на диск и обрезки
+type memFile struct {
+	bytes.Buffer
+	syncErr     error
+	truncateErr error
+}
+
+func (f *memFile) Sync() error { return f.syncErr }
+
+func (f *memFile) Truncate(size int64) error {
+	if f.truncateErr != nil {
+		return f.truncateErr
+	}
+	f.Buffer.Truncate(int(size))
+	return nil
+}
+
+func (f *memFile) Close() error { return nil }
+
+func TestWAL_SyncFailure(t *testing.T) {
+	f := &memFile{}
+	w := &wal{fsync: FsyncAlways, file: f}
+
+	require.NoError(t, w.append(walRecord{Op: opDeletePost, PostID: "p-0"}))
+	size := f.Len()
+
+	// неподтвержденная запись отрезается, номер записи не расходуется
+	f.syncErr = errors.New("sync failed")
+	require.ErrorContains(t, w.append(walRecord{Op: opDeletePost, PostID: "p-1"}), "failed to sync wal")
+	require.Equal(t, size, f.Len())
+	require.Equal(t, uint64(1), w.seq)
+
+	f.syncErr = nil
+	require.NoError(t, w.append(walRecord{Op: opDeletePost, PostID: "p-2"}))
+	require.Equal(t, uint64(2), w.seq)
+	require.NotContains(t, f.String(), `"p-1"`)
+
+	// запись не удалось отрезать: журнал больше не принимает изменения
+	f.syncErr, f.truncateErr = errors.New("sync failed"), errors.New("truncate failed")
+	require.Error(t, w.append(walRecord{Op: opDeletePost, PostID: "p-3"}))
+	f.syncErr, f.truncateErr = nil, nil
+	require.ErrorContains(t, w.append(walRecord{Op: opDeletePost, PostID: "p-4"}), "wal is broken")
+}
@@ -1,10 +1,10 @@
 package in_memory
 
 import (
+	"client-services/internal/apperr"
 	"client-services/internal/graph/model"
 	"context"
 	"fmt"
-	"sort"
 	"sync"
 	"time"
 
@@ -12,8 +12,16 @@ import (
 )
 
 type PostStorage struct {
-	posts map[string]*model.Post
-	mu    *sync.RWMutex
+	posts      map[string]*model.Post
+	order      *createdOrder
+	comments   map[string]*model.Comment
+	byPost     map[string]*createdOrder
+	children   map[threadKey]*createdOrder
+	deleted    map[string]int
+	now        func() time.Time
+	wal        *wal
+	mu         *sync.RWMutex
+	commentsMu *sync.RWMutex
 }
 
 func (s *InMemStorage) NewPostStorage() *PostStorage {
@@ -21,8 +29,16 @@ func (s *InMemStorage) NewPostStorage() *PostStorage {
 	_ = op
 
 	ps := &PostStorage{
-		posts: s.posts,
-		mu:    &s.mu,
+		posts:      s.posts,
+		order:      s.order,
+		comments:   s.comments,
+		byPost:     s.byPost,
+		children:   s.children,
+		deleted:    s.deleted,
+		now:        s.now,
+		wal:        s.wal,
+		mu:         &s.postsMu,
+		commentsMu: &s.commentsMu,
 	}
 
 	return ps
@@ -30,25 +46,37 @@ func (s *InMemStorage) NewPostStorage() *PostStorage {
 
 func (ps *PostStorage) SavePost(ctx context.Context, p *model.Post) (string, time.Time, error) {
 	const op = "storage.in-memory.SavePost"
-	_ = op
 
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
 	post := &model.Post{
 		ID:              uuid.New().String(),
+		AuthorID:        p.AuthorID,
 		Title:           p.Title,
 		Content:         p.Content,
 		Comments:        p.Comments,
 		CommentsAllowed: p.CommentsAllowed,
-		CreatedAt:       time.Now(),
+		Private:         p.Private,
+		CreatedAt:       ps.now(),
 	}
 
-	ps.posts[post.ID] = post
+	if err := ps.wal.append(walRecord{Op: opPutPost, Post: post}); err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	ps.putPost(post)
 
 	return post.ID, post.CreatedAt, nil
 }
 
+// putPost добавляет пост или заменяет сохраненную версию; вызывается под блокировкой записи
+func (ps *PostStorage) putPost(post *model.Post) {
+	if _, ok := ps.posts[post.ID]; !ok {
+		ps.order.insert(createdKey{createdAt: post.CreatedAt, id: post.ID})
+	}
+	ps.posts[post.ID] = post
+}
+
 func (ps *PostStorage) GetPost(ctx context.Context, id string) (*model.Post, error) {
 	const op = "storage.in-memory.GetPost"
 
@@ -58,10 +86,11 @@ func (ps *PostStorage) GetPost(ctx context.Context, id string) (*model.Post, err
 	post, ok := ps.posts[id]
 
 	if !ok {
-		return nil, fmt.Errorf("%s: post not found by id: %s", op, id)
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
 	}
 
-	return post, nil
+	p := *post
+	return &p, nil
 }
 
 func (ps *PostStorage) GetAllPosts(ctx context.Context) ([]model.Post, error) {
@@ -71,20 +100,165 @@ func (ps *PostStorage) GetAllPosts(ctx context.Context) ([]model.Post, error) {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
 
-	pCount := len(ps.posts)
+	posts := make([]model.Post, 0, len(ps.order.keys))
+	for _, key := range ps.order.keys {
+		posts = append(posts, *ps.posts[key.id])
+	}
 
-	if pCount == 0 {
-		return []model.Post{}, nil
+	return posts, nil
+}
+
+func (ps *PostStorage) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+	const op = "storage.in-memory.GetPosts"
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if first == nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` must not be negative"))
+	} else if *first == 0 {
+		return &[]model.Post{}, false, "", nil
 	}
 
-	posts := make([]model.Post, 0, pCount)
-	for _, p := range ps.posts {
-		posts = append(posts, *p)
+	keys := ps.order.keys
+
+	// позиция курсора находится бинарным поиском по его (createdAt, id)
+	cursor := -1
+	if after != nil && *after != "" {
+		post, ok := ps.posts[*after]
+		if !ok {
+			return nil, false, "", fmt.Errorf("%s: %w", op, apperr.ErrInvalidCursor)
+		}
+		cursor = ps.order.search(createdKey{createdAt: post.CreatedAt, id: post.ID})
 	}
 
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
-	})
+	pagePosts := make([]model.Post, 0, *first)
+	hasNextPage := false
+	if desc {
+		start := len(keys) - 1
+		if cursor >= 0 {
+			start = cursor - 1
+		}
+		for i := start; i >= 0; i-- {
+			if len(pagePosts) == int(*first) {
+				hasNextPage = true
+				break
+			}
+			pagePosts = append(pagePosts, *ps.posts[keys[i].id])
+		}
+	} else {
+		start := cursor + 1
+		for i := start; i < len(keys); i++ {
+			if len(pagePosts) == int(*first) {
+				hasNextPage = true
+				break
+			}
+			pagePosts = append(pagePosts, *ps.posts[keys[i].id])
+		}
+	}
 
-	return posts, nil
+	var endCursor string
+	if len(pagePosts) > 0 {
+		endCursor = pagePosts[len(pagePosts)-1].ID
+	}
+
+	return &pagePosts, hasNextPage, endCursor, nil
+}
+
+// посты в хранилище не изменяются на месте: при обновлении сохраняется новая копия,
+// поэтому ранее выданные указатели остаются согласованными
+func (ps *PostStorage) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	const op = "storage.in-memory.UpdatePost"
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	post, ok := ps.posts[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+	}
+
+	updated := *post
+	if title != nil {
+		updated.Title = *title
+	}
+	if content != nil {
+		updated.Content = *content
+	}
+	editedAt := ps.now()
+	updated.EditedAt = &editedAt
+
+	if err := ps.wal.append(walRecord{Op: opPutPost, Post: &updated}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	ps.putPost(&updated)
+
+	p := updated
+	return &p, nil
+}
+
+func (ps *PostStorage) DeletePost(ctx context.Context, id string) error {
+	const op = "storage.in-memory.DeletePost"
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.posts[id]; !ok {
+		return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+	}
+
+	if err := ps.wal.append(walRecord{Op: opDeletePost, PostID: id}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	ps.deletePost(id)
+
+	return nil
+}
+
+// deletePost удаляет пост вместе с комментариями; вызывается под блокировкой записи постов
+func (ps *PostStorage) deletePost(id string) {
+	post, ok := ps.posts[id]
+	if !ok {
+		return
+	}
+
+	ps.commentsMu.Lock()
+	if comments, ok := ps.byPost[id]; ok {
+		for _, key := range comments.keys {
+			delete(ps.children, threadKey{postID: id, parentID: key.id})
+			delete(ps.comments, key.id)
+		}
+	}
+	delete(ps.children, threadKey{postID: id})
+	delete(ps.byPost, id)
+	delete(ps.deleted, id)
+	ps.commentsMu.Unlock()
+
+	delete(ps.posts, id)
+	ps.order.remove(createdKey{createdAt: post.CreatedAt, id: post.ID})
+}
+
+func (ps *PostStorage) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error) {
+	const op = "storage.in-memory.SetCommentsAllowed"
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	post, ok := ps.posts[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+	}
+
+	updated := *post
+	updated.CommentsAllowed = allowed
+
+	if err := ps.wal.append(walRecord{Op: opPutPost, Post: &updated}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	ps.putPost(&updated)
+
+	p := updated
+	return &p, nil
 }
@@ -0,0 +1,22 @@
+package in_memory
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage"
+)
+
+func init() {
+	storage.Register("in-memory", func(cfg *config.Config, deps storage.Deps) (*storage.Backend, error) {
+		s, err := Open(&cfg.InMemory, deps.Log)
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage.Backend{
+			Storage:  s,
+			Posts:    s.NewPostStorage(),
+			Comments: s.NewCommentStorage(),
+			Users:    s.NewUserStorage(),
+		}, nil
+	})
+}
@@ -0,0 +1,120 @@
+package in_memory
+
+import (
+	"client-services/internal/graph/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const snapshotFile = "snapshot.json"
+
+// snapshot - полное состояние хранилища после записи журнала с номером Seq
+type snapshot struct {
+	Seq      uint64          `json:"seq"`
+	Posts    []model.Post    `json:"posts"`
+	Comments []model.Comment `json:"comments"`
+	Users    []persistedUser `json:"users"`
+}
+
+// takeSnapshot сохраняет состояние хранилища и удаляет вошедшие в снимок сегменты журнала.
+// Запись в хранилище блокируется только на время копирования состояния и смены сегмента.
+func (s *InMemStorage) takeSnapshot() error {
+	s.postsMu.RLock()
+	s.commentsMu.RLock()
+	s.usersMu.RLock()
+	snap := s.collect()
+	seq, err := s.wal.rotate()
+	s.usersMu.RUnlock()
+	s.commentsMu.RUnlock()
+	s.postsMu.RUnlock()
+	if err != nil {
+		return err
+	}
+	snap.Seq = seq
+
+	if err := writeSnapshot(s.wal.dir, snap); err != nil {
+		return err
+	}
+	return s.wal.truncate(seq)
+}
+
+// collect копирует состояние хранилища; вызывается под блокировкой
+func (s *InMemStorage) collect() *snapshot {
+	snap := &snapshot{
+		Posts:    make([]model.Post, 0, len(s.posts)),
+		Comments: make([]model.Comment, 0, len(s.comments)),
+		Users:    make([]persistedUser, 0, len(s.users)),
+	}
+
+	// комментарии поста идут в порядке создания, поэтому родитель восстанавливается раньше ответов
+	for _, key := range s.order.keys {
+		snap.Posts = append(snap.Posts, *s.posts[key.id])
+
+		if comments, ok := s.byPost[key.id]; ok {
+			for _, c := range comments.keys {
+				snap.Comments = append(snap.Comments, *s.comments[c.id])
+			}
+		}
+	}
+
+	for _, record := range s.users {
+		snap.Users = append(snap.Users, *record.persisted())
+	}
+
+	return snap
+}
+
+// writeSnapshot записывает снимок во временный файл и атомарно заменяет им предыдущий
+func writeSnapshot(dir string, snap *snapshot) error {
+	tmp, err := os.CreateTemp(dir, snapshotFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = json.NewEncoder(tmp).Encode(snap)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, snapshotFile)); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// readSnapshot читает последний снимок; если снимка нет, возвращает пустой
+func readSnapshot(dir string) (*snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// syncDir сохраняет на диск изменения каталога: создание и переименование файлов
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
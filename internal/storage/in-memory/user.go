@@ -0,0 +1,133 @@
+package in_memory
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserStorage struct {
+	users     map[string]*userRecord
+	usernames map[string]string
+	wal       *wal
+	mu        *sync.RWMutex
+}
+
+type userRecord struct {
+	user         model.User
+	passwordHash string
+}
+
+func (s *InMemStorage) NewUserStorage() *UserStorage {
+	const op = "storage.in-memory.NewUserStorage"
+	_ = op
+
+	us := &UserStorage{
+		users:     s.users,
+		usernames: s.usernames,
+		wal:       s.wal,
+		mu:        &s.usersMu,
+	}
+
+	return us
+}
+
+func (us *UserStorage) SaveUser(ctx context.Context, u *model.User, passwordHash string) (string, time.Time, error) {
+	const op = "storage.in-memory.SaveUser"
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	if _, ok := us.usernames[u.Username]; ok {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, apperr.ErrUserExists)
+	}
+
+	record := &userRecord{
+		user: model.User{
+			ID:        uuid.New().String(),
+			Username:  u.Username,
+			Role:      u.Role,
+			CreatedAt: time.Now(),
+		},
+		passwordHash: passwordHash,
+	}
+
+	if err := us.wal.append(walRecord{Op: opPutUser, User: record.persisted()}); err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	us.putUser(record)
+
+	return record.user.ID, record.user.CreatedAt, nil
+}
+
+// putUser добавляет пользователя или заменяет сохраненную запись; вызывается под блокировкой записи
+func (us *UserStorage) putUser(record *userRecord) {
+	us.users[record.user.ID] = record
+	us.usernames[record.user.Username] = record.user.ID
+}
+
+func (r *userRecord) persisted() *persistedUser {
+	return &persistedUser{User: r.user, PasswordHash: r.passwordHash}
+}
+
+func (us *UserStorage) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	const op = "storage.in-memory.GetUserByName"
+
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	id, ok := us.usernames[username]
+	if !ok {
+		return nil, "", fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+	}
+
+	record := us.users[id]
+	user := record.user
+	return &user, record.passwordHash, nil
+}
+
+func (us *UserStorage) GetUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	const op = "storage.in-memory.GetUsers"
+	_ = op
+
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	users := make(map[string]*model.User, len(ids))
+	for _, id := range ids {
+		if record, ok := us.users[id]; ok {
+			user := record.user
+			users[id] = &user
+		}
+	}
+
+	return users, nil
+}
+
+func (us *UserStorage) SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error) {
+	const op = "storage.in-memory.SetUserRole"
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	record, ok := us.users[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+	}
+
+	updated := *record
+	updated.user.Role = role
+
+	if err := us.wal.append(walRecord{Op: opPutUser, User: updated.persisted()}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	us.putUser(&updated)
+
+	user := updated.user
+	return &user, nil
+}
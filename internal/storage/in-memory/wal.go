@@ -0,0 +1,251 @@
+package in_memory
+
+import (
+	"bufio"
+	"client-services/internal/graph/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// политики fsync журнала
+const (
+	FsyncAlways   = "always"
+	FsyncEverySec = "everysec"
+	FsyncNever    = "never"
+)
+
+type walOp string
+
+// записи журнала хранят итоговое состояние объекта, поэтому повторное применение записи ничего не меняет
+const (
+	opPutPost    walOp = "put_post"
+	opDeletePost walOp = "delete_post"
+	opPutComment walOp = "put_comment"
+	opPutUser    walOp = "put_user"
+)
+
+type walRecord struct {
+	Seq     uint64         `json:"seq"`
+	Op      walOp          `json:"op"`
+	Post    *model.Post    `json:"post,omitempty"`
+	PostID  string         `json:"postID,omitempty"`
+	Comment *model.Comment `json:"comment,omitempty"`
+	User    *persistedUser `json:"user,omitempty"`
+}
+
+type persistedUser struct {
+	User         model.User `json:"user"`
+	PasswordHash string     `json:"passwordHash"`
+}
+
+// wal - журнал изменений хранилища. Журнал разбит на сегменты wal-<seq>.log, где seq - номер первой записи сегмента;
+// при снимке начинается новый сегмент, а сегменты, полностью вошедшие в снимок, удаляются.
+// Записи добавляются под блокировкой записи хранилища, поэтому порядок в журнале совпадает с порядком изменений.
+type wal struct {
+	dir   string
+	fsync string
+
+	mu    sync.Mutex
+	file  walFile
+	size  int64
+	seq   uint64
+	dirty bool
+	// broken - ошибка, после которой в сегменте могла остаться неподтвержденная запись; дальнейшие записи отклоняются
+	broken error
+}
+
+// walFile - открытый сегмент журнала; интерфейс позволяет тестам подменить ошибки файловой системы
+type walFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+const walMaxRecord = 1 << 20
+
+func segmentName(firstSeq uint64) string {
+	return fmt.Sprintf("wal-%020d.log", firstSeq)
+}
+
+// segments возвращает сегменты журнала в каталоге dir в порядке записи
+func segments(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// append записывает изменение в журнал до его применения к хранилищу.
+// Хранилище без сохранения на диск (nil-журнал) ничего не записывает.
+func (w *wal) append(rec walRecord) error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.broken != nil {
+		return fmt.Errorf("wal is broken: %w", w.broken)
+	}
+
+	rec.Seq = w.seq + 1
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode wal record: %w", err)
+	}
+
+	n, err := w.file.Write(append(line, '\n'))
+	if err != nil {
+		// частично записанная строка отрезается, чтобы следующие записи не оказались за ней
+		if n > 0 {
+			w.discard(err)
+		}
+		return fmt.Errorf("failed to write wal: %w", err)
+	}
+
+	if w.fsync == FsyncAlways {
+		// изменение не применяется к хранилищу, поэтому неподтвержденная запись не должна попасть в восстановление
+		if err := w.file.Sync(); err != nil {
+			w.discard(err)
+			return fmt.Errorf("failed to sync wal: %w", err)
+		}
+	} else {
+		w.dirty = true
+	}
+	w.size += int64(n)
+	w.seq = rec.Seq
+
+	return nil
+}
+
+// discard отрезает от сегмента запись, добавленную после w.size.
+// Если отрезать не удалось, журнал помечается сломанным: иначе после перезапуска восстановилось бы отклоненное изменение.
+func (w *wal) discard(cause error) {
+	if err := w.file.Truncate(w.size); err != nil {
+		w.broken = errors.Join(cause, err)
+	}
+}
+
+// sync сбрасывает на диск записи, добавленные после предыдущего вызова
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.dirty {
+		return nil
+	}
+	w.dirty = false
+	return w.file.Sync()
+}
+
+// rotate закрывает текущий сегмент и начинает новый; возвращает номер последней записи закрытого сегмента
+func (w *wal) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// сегмент с тем же именем может существовать, только если в нем нет ни одной полной записи
+	file, err := os.OpenFile(filepath.Join(w.dir, segmentName(w.seq+1)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open wal segment: %w", err)
+	}
+
+	if w.file != nil {
+		if err := w.file.Sync(); err != nil {
+			file.Close()
+			return 0, fmt.Errorf("failed to sync wal: %w", err)
+		}
+		w.file.Close()
+	}
+	w.file = file
+	w.size = 0
+	w.dirty = false
+
+	return w.seq, nil
+}
+
+// truncate удаляет сегменты, все записи которых не новее seq
+func (w *wal) truncate(seq uint64) error {
+	files, err := segments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	keep := filepath.Join(w.dir, segmentName(seq+1))
+	for _, file := range files {
+		if file >= keep {
+			break
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.file.Sync(), w.file.Close())
+	w.file = nil
+	return err
+}
+
+// readSegment передает в apply записи сегмента по порядку.
+// Неполная последняя строка возможна после аварийного завершения: при allowTorn она отрезается от файла.
+func readSegment(path string, allowTorn bool, apply func(rec walRecord) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var size int64
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) == 0 {
+				return nil
+			}
+			// запись без перевода строки не была дописана до конца
+			if !allowTorn {
+				return fmt.Errorf("%s:%d: incomplete record", filepath.Base(path), line)
+			}
+			if err := os.Truncate(path, size); err != nil {
+				return err
+			}
+			return errTornRecord
+		}
+		if err != nil {
+			return err
+		}
+		size += int64(len(data))
+		if len(data) > walMaxRecord {
+			return fmt.Errorf("%s:%d: record is too large", filepath.Base(path), line)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("%s:%d: %w", filepath.Base(path), line, err)
+		}
+		if err := apply(rec); err != nil {
+			return fmt.Errorf("%s:%d: %w", filepath.Base(path), line, err)
+		}
+	}
+}
+
+var errTornRecord = errors.New("incomplete last record")
@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/go-pg/pg/v10"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsLockID - ключ advisory-блокировки, под которой применяются миграции.
+// Несколько экземпляров сервиса, стартующих одновременно, применяют миграции по очереди.
+const migrationsLockID int64 = 7_243_190_511
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator применяет встроенные в бинарный файл миграции из каталога migrations.
+// Примененные версии хранятся в таблице schema_migrations.
+type Migrator struct {
+	db         *pg.DB
+	log        *slog.Logger
+	migrations []Migration
+}
+
+func NewMigrator(s *Storage, log *slog.Logger) (*Migrator, error) {
+	const op = "storage.postgres.NewMigrator"
+
+	migrations, err := loadMigrations(migrationsFS)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{
+		db:         &s.DB,
+		log:        log,
+		migrations: migrations,
+	}, nil
+}
+
+// loadMigrations читает пары файлов NNNN_name.up.sql / NNNN_name.down.sql и сортирует их по версии
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		parts := migrationName.FindStringSubmatch(path.Base(file))
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", file, err)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if m.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has different names: %q and %q", version, m.Name, parts[2])
+		}
+
+		if parts[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up применяет все еще не примененные миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	const op = "storage.postgres.Migrator.Up"
+
+	return m.withLock(ctx, func(conn *pg.Conn, applied map[int64]bool) error {
+		for _, migration := range m.migrations {
+			if applied[migration.Version] {
+				continue
+			}
+
+			err := conn.RunInTransaction(ctx, func(tx *pg.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+					migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s: failed to apply migration %d_%s: %w", op, migration.Version, migration.Name, err)
+			}
+
+			m.log.Info("migration applied",
+				slog.Int64("version", migration.Version),
+				slog.String("name", migration.Name),
+			)
+		}
+		return nil
+	})
+}
+
+// Down откатывает steps последних примененных миграций
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	const op = "storage.postgres.Migrator.Down"
+
+	return m.withLock(ctx, func(conn *pg.Conn, applied map[int64]bool) error {
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if !applied[migration.Version] {
+				continue
+			}
+
+			err := conn.RunInTransaction(ctx, func(tx *pg.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					"DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%s: failed to revert migration %d_%s: %w", op, migration.Version, migration.Name, err)
+			}
+
+			m.log.Info("migration reverted",
+				slog.Int64("version", migration.Version),
+				slog.String("name", migration.Name),
+			)
+			steps--
+		}
+		return nil
+	})
+}
+
+// Version возвращает номер последней примененной миграции; 0, если миграции не применялись
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.Migrator.Version"
+	var version int64
+
+	err := m.withLock(ctx, func(conn *pg.Conn, applied map[int64]bool) error {
+		for v := range applied {
+			version = max(version, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// withLock выполняет fn на отдельном соединении под advisory-блокировкой.
+// Блокировка принадлежит сессии, поэтому все запросы выполняются через одно соединение.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pg.Conn, applied map[int64]bool) error) error {
+	conn := m.db.Conn()
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", migrationsLockID); err != nil {
+		return fmt.Errorf("failed to acquire migrations lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", migrationsLockID); err != nil {
+			m.log.Error("failed to release migrations lock", slog.String("error", err.Error()))
+		}
+	}()
+
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var versions []int64
+	if _, err := conn.QueryContext(ctx, &versions, "SELECT version FROM schema_migrations"); err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	return fn(conn, applied)
+}
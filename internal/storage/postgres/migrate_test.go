@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(migrationsFS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		require.Equal(t, int64(i+1), m.Version, "migration versions must be consecutive")
+		require.NotEmpty(t, m.Up)
+		require.NotEmpty(t, m.Down)
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		tFiles fstest.MapFS
+		tErr   string
+	}{
+		{
+			fstest.MapFS{"migrations/0001_init.up.sql": {Data: []byte("SELECT 1")}},
+			"must have both up and down files",
+		},
+		{
+			fstest.MapFS{"migrations/init.up.sql": {Data: []byte("SELECT 1")}},
+			"invalid migration file name",
+		},
+		{
+			fstest.MapFS{
+				"migrations/0001_init.up.sql":    {Data: []byte("SELECT 1")},
+				"migrations/0001_other.down.sql": {Data: []byte("SELECT 1")},
+			},
+			"has different names",
+		},
+	}
+
+	for _, tt := range tests {
+		migrations, err := loadMigrations(tt.tFiles)
+		require.Error(t, err)
+		require.Nil(t, migrations)
+		require.Contains(t, err.Error(), tt.tErr)
+	}
+}
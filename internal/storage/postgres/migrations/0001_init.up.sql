@@ -0,0 +1,16 @@
+-- схема, которую раньше создавал CreateTable; IF NOT EXISTS позволяет применить миграцию к существующей базе
+CREATE TABLE IF NOT EXISTS posts (
+	id text PRIMARY KEY,
+	title text,
+	content text,
+	comments_allowed boolean,
+	created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+	id text PRIMARY KEY,
+	post_id text,
+	parent_id text,
+	content text,
+	created_at timestamptz
+);
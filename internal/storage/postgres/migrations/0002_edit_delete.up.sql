@@ -0,0 +1,3 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at timestamptz;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at timestamptz;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS post_stats (
+	post_id text PRIMARY KEY,
+	comments_count integer NOT NULL DEFAULT 0
+);
+
+-- существующие счетчики не пересчитываются
+INSERT INTO post_stats (post_id, comments_count)
+SELECT post_id, count(*) FROM comments GROUP BY post_id
+ON CONFLICT (post_id) DO NOTHING;
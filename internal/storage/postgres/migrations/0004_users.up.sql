@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users (
+	id text PRIMARY KEY,
+	username text NOT NULL UNIQUE,
+	password_hash text NOT NULL,
+	created_at timestamptz NOT NULL
+);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS author_id text;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS author_id text;
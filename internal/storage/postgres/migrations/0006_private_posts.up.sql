@@ -0,0 +1 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS private boolean NOT NULL DEFAULT false;
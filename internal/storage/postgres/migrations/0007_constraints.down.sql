@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS posts_created_at_id_idx;
+DROP INDEX IF EXISTS comments_post_id_parent_id_created_at_id_idx;
+DROP INDEX IF EXISTS comments_post_id_created_at_id_idx;
+
+ALTER TABLE post_stats DROP CONSTRAINT IF EXISTS post_stats_post_id_fkey;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_fkey;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_post_id_id_key;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_author_id_fkey;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_post_id_fkey;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_author_id_fkey;
+
+ALTER TABLE comments ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE comments ALTER COLUMN post_id DROP NOT NULL;
+ALTER TABLE posts ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE posts ALTER COLUMN content DROP NOT NULL;
+ALTER TABLE posts ALTER COLUMN title DROP NOT NULL;
@@ -0,0 +1,39 @@
+-- ограничения целостности и индексы для пагинации;
+-- если в базе есть комментарии без поста или ответы без родителя, миграция завершится ошибкой и не будет применена
+
+ALTER TABLE posts ALTER COLUMN title SET NOT NULL;
+ALTER TABLE posts ALTER COLUMN content SET NOT NULL;
+ALTER TABLE posts ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE comments ALTER COLUMN post_id SET NOT NULL;
+ALTER TABLE comments ALTER COLUMN created_at SET NOT NULL;
+
+ALTER TABLE posts
+	ADD CONSTRAINT posts_author_id_fkey
+	FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE comments
+	ADD CONSTRAINT comments_post_id_fkey
+	FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE;
+
+ALTER TABLE comments
+	ADD CONSTRAINT comments_author_id_fkey
+	FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE SET NULL;
+
+-- родительский комментарий должен принадлежать тому же посту
+ALTER TABLE comments
+	ADD CONSTRAINT comments_post_id_id_key UNIQUE (post_id, id);
+
+ALTER TABLE comments
+	ADD CONSTRAINT comments_parent_fkey
+	FOREIGN KEY (post_id, parent_id) REFERENCES comments (post_id, id) ON DELETE CASCADE;
+
+ALTER TABLE post_stats
+	ADD CONSTRAINT post_stats_post_id_fkey
+	FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE;
+
+-- GetComments, GetCommentsBatch
+CREATE INDEX IF NOT EXISTS comments_post_id_created_at_id_idx ON comments (post_id, created_at, id);
+-- GetReplies, GetThread и проверка comments_parent_fkey
+CREATE INDEX IF NOT EXISTS comments_post_id_parent_id_created_at_id_idx ON comments (post_id, parent_id, created_at, id);
+-- GetPosts
+CREATE INDEX IF NOT EXISTS posts_created_at_id_idx ON posts (created_at, id);
@@ -0,0 +1,3 @@
+UPDATE post_stats SET comments_count = (
+	SELECT count(*) FROM comments WHERE comments.post_id = post_stats.post_id
+);
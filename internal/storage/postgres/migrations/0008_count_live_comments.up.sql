@@ -0,0 +1,5 @@
+-- удаленные комментарии больше не входят в счетчик
+UPDATE post_stats SET comments_count = (
+	SELECT count(*) FROM comments
+	WHERE comments.post_id = post_stats.post_id AND comments.deleted_at IS NULL
+);
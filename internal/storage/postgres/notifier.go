@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"client-services/internal/graph/model"
+	"client-services/internal/notify"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	notifyChannel = "comments_updated"
+	// максимальный размер payload у NOTIFY - 8000 байт
+	maxNotifyPayload = 7999
+)
+
+// Notifier - шина уведомлений поверх LISTEN/NOTIFY.
+// Publish отправляет событие через NOTIFY, а слушатель каждого экземпляра сервиса
+// (включая отправивший) получает его и раздает локальным подписчикам через notify.Hub.
+// Слушатель закрывается вместе с хранилищем в Storage.CloseDB.
+type Notifier struct {
+	db  *pg.DB
+	hub *notify.Hub
+	log *slog.Logger
+
+	listener *pg.Listener
+}
+
+func NewNotifier(s *Storage, hub *notify.Hub, log *slog.Logger) *Notifier {
+	n := &Notifier{
+		db:       &s.DB,
+		hub:      hub,
+		log:      log,
+		listener: s.DB.Listen(context.Background(), notifyChannel),
+	}
+
+	s.notifier = n
+	go n.listen()
+
+	return n
+}
+
+func (n *Notifier) Publish(ctx context.Context, c *model.CommentNotify) error {
+	const op = "storage.postgres.notifier.Publish"
+
+	payload, err := encodeNotify(c)
+	if err != nil {
+		return fmt.Errorf("%s: failed to marshal notify: %w", op, err)
+	}
+
+	if _, err := n.db.ExecContext(ctx, "SELECT pg_notify(?, ?)", notifyChannel, payload); err != nil {
+		return fmt.Errorf("%s: failed to send notify: %w", op, err)
+	}
+
+	return nil
+}
+
+// encodeNotify кодирует уведомление для NOTIFY. Если payload не помещается в лимит,
+// текст комментария не передается: получатель загрузит его из базы.
+func encodeNotify(c *model.CommentNotify) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	if len(payload) > maxNotifyPayload {
+		short := *c
+		short.Content = ""
+		if payload, err = json.Marshal(&short); err != nil {
+			return "", err
+		}
+	}
+
+	return string(payload), nil
+}
+
+func (n *Notifier) Subscribe(ctx context.Context, postID string) (<-chan *model.CommentNotify, error) {
+	return n.hub.Subscribe(ctx, postID)
+}
+
+func (n *Notifier) Close() error {
+	return n.listener.Close()
+}
+
+func (n *Notifier) listen() {
+	const op = "storage.postgres.notifier.listen"
+
+	for msg := range n.listener.Channel() {
+		var c model.CommentNotify
+		if err := json.Unmarshal([]byte(msg.Payload), &c); err != nil {
+			n.log.Error("failed to unmarshal notify",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		if c.Event == model.NotifyEventCommentAdded && c.Content == "" {
+			_, err := n.db.QueryOne(pg.Scan(&c.Content), "SELECT content FROM comments WHERE id = ?", c.ID)
+			if err != nil {
+				n.log.Error("failed to load comment content for notify",
+					slog.String("op", op),
+					slog.String("commentID", c.ID),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+		}
+
+		_ = n.hub.Publish(context.Background(), &c)
+	}
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"client-services/internal/graph/model"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeNotify(t *testing.T) {
+	n := &model.CommentNotify{PostID: "p-0", ID: "c-0", Content: "short", Event: model.NotifyEventCommentAdded}
+
+	payload, err := encodeNotify(n)
+	require.NoError(t, err)
+	var decoded model.CommentNotify
+	require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+	require.Equal(t, *n, decoded)
+
+	// длинный текст не помещается в NOTIFY: получатель загрузит его из базы
+	long := &model.CommentNotify{PostID: "p-0", ID: "c-1", Content: strings.Repeat("я", maxNotifyPayload), Event: model.NotifyEventCommentAdded}
+	payload, err = encodeNotify(long)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(payload), maxNotifyPayload)
+
+	decoded = model.CommentNotify{}
+	require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+	require.Empty(t, decoded.Content)
+	require.Equal(t, "c-1", decoded.ID)
+	require.Equal(t, "p-0", decoded.PostID)
+	require.Equal(t, model.NotifyEventCommentAdded, decoded.Event)
+
+	// исходное уведомление не изменяется
+	require.NotEmpty(t, long.Content)
+}
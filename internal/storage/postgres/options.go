@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"client-services/internal/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// newOptions переводит настройки подключения из конфигурации в pg.Options
+func newOptions(cfg config.StorageConnect) (*pg.Options, error) {
+	if cfg.SQLDriver != "" && cfg.SQLDriver != "postgres" {
+		return nil, fmt.Errorf("unsupported sql driver %q", cfg.SQLDriver)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.SQLSSLMode, cfg.SQLSSLRootCert, cfg.SQLAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &pg.Options{
+		Addr:            fmt.Sprintf("%s:%s", cfg.SQLAddress, cfg.SQLPort),
+		User:            cfg.SQLUser,
+		Password:        cfg.SQLPassword,
+		Database:        cfg.SQLDBName,
+		ApplicationName: cfg.ApplicationName,
+		TLSConfig:       tlsConfig,
+		PoolSize:        cfg.PoolSize,
+		MinIdleConns:    cfg.MinIdleConns,
+		MaxConnAge:      cfg.MaxConnAge,
+		DialTimeout:     cfg.DialTimeout,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+	}
+
+	if cfg.StatementTimeout > 0 {
+		timeout := cfg.StatementTimeout.Milliseconds()
+		opts.OnConnect = func(ctx context.Context, cn *pg.Conn) error {
+			_, err := cn.ExecContext(ctx, "SET statement_timeout = ?", timeout)
+			return err
+		}
+	}
+
+	return opts, nil
+}
+
+// newTLSConfig повторяет режимы sslmode libpq:
+//   - disable - без TLS;
+//   - require - TLS без проверки сертификата сервера, но с проверкой цепочки, если задан CA-сертификат;
+//   - verify-ca - проверка цепочки сертификата сервера;
+//   - verify-full - проверка цепочки и имени сервера.
+func newTLSConfig(mode string, rootCertFile string, host string) (*tls.Config, error) {
+	switch mode {
+	case "", "disable":
+		return nil, nil
+	case "require", "verify-ca", "verify-full":
+	default:
+		return nil, fmt.Errorf("unsupported sslmode %q", mode)
+	}
+
+	var roots *x509.CertPool
+	if rootCertFile != "" {
+		pem, err := os.ReadFile(rootCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root certificate: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %q", rootCertFile)
+		}
+	} else if mode != "require" {
+		// без CA-сертификата проверка выполняется по системному хранилищу
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system certificates: %w", err)
+		}
+		roots = pool
+	}
+
+	if mode == "verify-full" {
+		return &tls.Config{
+			RootCAs:    roots,
+			ServerName: host,
+			MinVersion: tls.VersionTLS12,
+		}, nil
+	}
+
+	cfg := &tls.Config{
+		// имя сервера не проверяется; цепочка проверяется в VerifyConnection, если есть корневые сертификаты
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if roots != nil {
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyChain(cs, roots)
+		}
+	}
+	return cfg, nil
+}
+
+func verifyChain(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server did not provide a certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"client-services/internal/config"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCA выпускает CA и подписанный им сертификат сервера для имени dnsName
+func testCA(t *testing.T, dnsName string) (caFile string, serverCert tls.Certificate) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	srvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	srvTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	srvDER, err := x509.CreateCertificate(rand.Reader, srvTmpl, caTmpl, &srvKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caFile = filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+
+	return caFile, tls.Certificate{Certificate: [][]byte{srvDER}, PrivateKey: srvKey}
+}
+
+// handshake выполняет TLS-рукопожатие клиента с конфигурацией cfg и сервера с сертификатом cert
+func handshake(t *testing.T, cfg *tls.Config, cert tls.Certificate) error {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+
+	clientConn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+	require.NoError(t, err)
+	defer clientConn.Close()
+	require.NoError(t, clientConn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	return tls.Client(clientConn, cfg).Handshake()
+}
+
+func TestNewOptions(t *testing.T) {
+	opts, err := newOptions(config.StorageConnect{
+		SQLDriver:        "postgres",
+		SQLAddress:       "db",
+		SQLPort:          "5432",
+		SQLSSLMode:       "disable",
+		ApplicationName:  "client-services",
+		PoolSize:         20,
+		MinIdleConns:     2,
+		MaxConnAge:       time.Hour,
+		DialTimeout:      3 * time.Second,
+		StatementTimeout: 30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "db:5432", opts.Addr)
+	require.Equal(t, "client-services", opts.ApplicationName)
+	require.Equal(t, 20, opts.PoolSize)
+	require.Equal(t, 2, opts.MinIdleConns)
+	require.Equal(t, time.Hour, opts.MaxConnAge)
+	require.Equal(t, 3*time.Second, opts.DialTimeout)
+	require.Nil(t, opts.TLSConfig)
+	require.NotNil(t, opts.OnConnect)
+}
+
+func TestNewOptions_Invalid(t *testing.T) {
+	_, err := newOptions(config.StorageConnect{SQLDriver: "mysql"})
+	require.ErrorContains(t, err, "unsupported sql driver")
+
+	_, err = newOptions(config.StorageConnect{SQLDriver: "postgres", SQLSSLMode: "prefer"})
+	require.ErrorContains(t, err, "unsupported sslmode")
+
+	_, err = newOptions(config.StorageConnect{SQLDriver: "postgres", SQLSSLMode: "verify-ca", SQLSSLRootCert: "missing.pem"})
+	require.ErrorContains(t, err, "failed to read root certificate")
+}
+
+func TestNewTLSConfig_Modes(t *testing.T) {
+	caFile, cert := testCA(t, "db.internal")
+	_, otherCert := testCA(t, "db.internal")
+
+	tests := []struct {
+		tName   string
+		tMode   string
+		tCAFile string
+		tHost   string
+		tCert   tls.Certificate
+		tOK     bool
+	}{
+		{"require без CA", "require", "", "db.internal", otherCert, true},
+		{"require с CA", "require", caFile, "db.internal", otherCert, false},
+		{"verify-ca", "verify-ca", caFile, "10.0.0.1", cert, true},
+		{"verify-ca чужой CA", "verify-ca", caFile, "db.internal", otherCert, false},
+		{"verify-full", "verify-full", caFile, "db.internal", cert, true},
+		{"verify-full другое имя", "verify-full", caFile, "other.internal", cert, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			cfg, err := newTLSConfig(tt.tMode, tt.tCAFile, tt.tHost)
+			require.NoError(t, err)
+
+			err = handshake(t, cfg, tt.tCert)
+			if tt.tOK {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -2,65 +2,117 @@ package postgres
 
 import (
 	"client-services/internal/config"
-	"client-services/internal/graph/model"
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/go-pg/pg/v10"
-	"github.com/go-pg/pg/v10/orm"
 )
 
+// время ожидания миграций при старте, включая ожидание блокировки, занятой другим экземпляром
+const migrateTimeout = time.Minute
+
 type Storage struct {
 	DB pg.DB
+	// реплики для запросов только на чтение
+	Replicas []*pg.DB
+	// слушатель LISTEN/NOTIFY; закрывается вместе с базой
+	notifier *Notifier
 }
 
+// NewStorage подключается к базе и применяет недостающие миграции
 func NewStorage(cfg config.StorageConnect) (*Storage, error) {
 	const op = "storage.postgres.NewStorage"
 
-	BDAddr := fmt.Sprintf("%s:%s", cfg.SQLAddress, cfg.SQLPort)
-	conn := pg.Connect(&pg.Options{
-		Addr:     BDAddr,
-		User:     cfg.SQLUser,
-		Password: cfg.SQLPassword,
-		Database: cfg.SQLDBName,
-	})
+	s, err := Connect(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	migrator, err := NewMigrator(s, slog.Default())
+	if err != nil {
+		s.CloseDB()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	if err := migrator.Up(ctx); err != nil {
+		s.CloseDB()
+		return nil, fmt.Errorf("%s: failed to migrate: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// Connect подключается к базе без применения миграций
+func Connect(cfg config.StorageConnect) (*Storage, error) {
+	const op = "storage.postgres.Connect"
+
+	opts, err := newOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	conn := pg.Connect(opts)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
 	if err := conn.Ping(ctx); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("%s: failed to connect database: %w", op, err)
 	}
 
-	s := &Storage{DB: *conn}
-
-	if err := migrate(s); err != nil {
-		return nil, fmt.Errorf("%s: failed to migrate: %w", op, err)
+	replicas, err := connectReplicas(ctx, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return s, nil
+	return &Storage{DB: *conn, Replicas: replicas}, nil
 }
 
-func migrate(s *Storage) error {
-	const op = "storage.postgres.migrate"
-	_ = op
+// connectReplicas подключается к репликам с параметрами основной базы.
+// Недоступная при старте реплика не мешает запуску: запросы к ней переходят на основную базу.
+func connectReplicas(ctx context.Context, cfg config.StorageConnect) ([]*pg.DB, error) {
+	replicas := make([]*pg.DB, 0, len(cfg.Replicas))
 
-	schemas := []interface{}{
-		(*model.Post)(nil),
-		(*model.Comment)(nil),
-	}
+	for _, replica := range cfg.Replicas {
+		replicaCfg := cfg
+		replicaCfg.SQLAddress = replica.Address
+		if replica.Port != "" {
+			replicaCfg.SQLPort = replica.Port
+		}
 
-	for _, schem := range schemas {
-		err := s.DB.Model(schem).CreateTable(&orm.CreateTableOptions{IfNotExists: true})
+		opts, err := newOptions(replicaCfg)
 		if err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
+			for _, db := range replicas {
+				db.Close()
+			}
+			return nil, fmt.Errorf("replica %s: %w", replica.Address, err)
+		}
+		db := pg.Connect(opts)
+
+		if err := db.Ping(ctx); err != nil {
+			slog.Warn("read replica is unavailable",
+				slog.String("addr", opts.Addr),
+				slog.String("error", err.Error()),
+			)
 		}
+		replicas = append(replicas, db)
 	}
 
-	return nil
+	return replicas, nil
 }
 
 func (s *Storage) CloseDB() error {
+	if s.notifier != nil {
+		s.notifier.Close()
+	}
+	for _, db := range s.Replicas {
+		db.Close()
+	}
 	return s.DB.Close()
 }
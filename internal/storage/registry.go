@@ -0,0 +1,102 @@
+// Package storage - реестр хранилищ. Каждое хранилище регистрирует фабрику под своим именем
+// в init() своего пакета, а сервис выбирает фабрику по полю storage конфигурации.
+// Чтобы хранилище было доступно, его пакет подключается пустым импортом в cmd/client-services.
+package storage
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph"
+	"client-services/internal/metrics"
+	"client-services/internal/notify"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Backend - реализации, которые хранилище предоставляет резолверу
+type Backend struct {
+	// Storage закрывает хранилище при остановке сервиса
+	Storage  graph.StorageInterface
+	Posts    graph.PostInterface
+	Comments graph.CommentInterface
+	Users    graph.UserInterface
+	// Notifier - собственная шина уведомлений хранилища; nil, если уведомления раздаются локально
+	Notifier graph.NotifierInterface
+}
+
+// Deps - общие зависимости, которые сервис передает фабрике хранилища
+type Deps struct {
+	Log *slog.Logger
+	// Hub раздает уведомления подписчикам этого экземпляра сервиса
+	Hub *notify.Hub
+	// Metrics - метрики сервиса; nil, если метрики не собираются
+	Metrics *metrics.Metrics
+}
+
+// Factory создает хранилище по конфигурации сервиса.
+// Сторонние хранилища могут читать свои параметры из cfg.StorageOptions.
+type Factory func(cfg *config.Config, deps Deps) (*Backend, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register регистрирует фабрику хранилища под именем name.
+// Повторная регистрация имени считается ошибкой программы и приводит к панике.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if name == "" {
+		panic("storage: Register with empty name")
+	}
+	if factory == nil {
+		panic("storage: Register factory is nil for " + name)
+	}
+	if _, ok := factories[name]; ok {
+		panic("storage: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Names возвращает отсортированные имена зарегистрированных хранилищ
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open создает хранилище, зарегистрированное под именем name
+func Open(name string, cfg *config.Config, deps Deps) (*Backend, error) {
+	const op = "storage.Open"
+
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown storage type %q, registered: %s", op, name, strings.Join(Names(), ", "))
+	}
+
+	backend, err := factory(cfg, deps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to initialize %s storage: %w", op, name, err)
+	}
+
+	if backend.Storage == nil || backend.Posts == nil || backend.Comments == nil || backend.Users == nil {
+		if backend.Storage != nil {
+			backend.Storage.CloseDB()
+		}
+		return nil, fmt.Errorf("%s: %s storage is incomplete", op, name)
+	}
+
+	return backend, nil
+}
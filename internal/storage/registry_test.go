@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/graph/mocks"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	closer := mocks.NewMockStorageInterface(ctrl)
+
+	Register("test-complete", func(cfg *config.Config, deps Deps) (*Backend, error) {
+		return &Backend{
+			Storage:  closer,
+			Posts:    mocks.NewMockPostInterface(ctrl),
+			Comments: mocks.NewMockCommentInterface(ctrl),
+			Users:    mocks.NewMockUserInterface(ctrl),
+		}, nil
+	})
+	Register("test-incomplete", func(cfg *config.Config, deps Deps) (*Backend, error) {
+		return &Backend{Storage: closer}, nil
+	})
+	errFactory := errors.New("connection refused")
+	Register("test-failing", func(cfg *config.Config, deps Deps) (*Backend, error) {
+		return nil, errFactory
+	})
+
+	require.Subset(t, Names(), []string{"test-complete", "test-failing", "test-incomplete"})
+	require.IsIncreasing(t, Names())
+
+	backend, err := Open("test-complete", &config.Config{}, Deps{})
+	require.NoError(t, err)
+	require.Nil(t, backend.Notifier)
+
+	// неполное хранилище закрывается и не возвращается
+	closer.EXPECT().CloseDB().Return(nil)
+	_, err = Open("test-incomplete", &config.Config{}, Deps{})
+	require.ErrorContains(t, err, "incomplete")
+
+	_, err = Open("test-failing", &config.Config{}, Deps{})
+	require.ErrorIs(t, err, errFactory)
+
+	_, err = Open("missing", &config.Config{}, Deps{})
+	require.ErrorContains(t, err, `unknown storage type "missing"`)
+	require.ErrorContains(t, err, "test-complete")
+
+	require.PanicsWithValue(t, "storage: Register called twice for test-complete", func() {
+		Register("test-complete", func(cfg *config.Config, deps Deps) (*Backend, error) { return nil, nil })
+	})
+	require.Panics(t, func() { Register("test-nil", nil) })
+	require.Panics(t, func() {
+		Register("", func(cfg *config.Config, deps Deps) (*Backend, error) { return nil, nil })
+	})
+}
@@ -0,0 +1,433 @@
+package sqlite
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const commentColumns = "id, post_id, parent_id, author_id, content, created_at, edited_at, deleted_at"
+
+type CommentStorage struct {
+	s *Storage
+}
+
+func (s *Storage) NewCommentStorage() *CommentStorage {
+	return &CommentStorage{s: s}
+}
+
+func scanComment(row scanner) (model.Comment, error) {
+	var (
+		comment   model.Comment
+		parentID  sql.NullString
+		authorID  sql.NullString
+		createdAt int64
+		editedAt  sql.NullInt64
+		deletedAt sql.NullInt64
+	)
+
+	err := row.Scan(&comment.ID, &comment.PostID, &parentID, &authorID, &comment.Content,
+		&createdAt, &editedAt, &deletedAt)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	comment.ParentID = stringPtr(parentID)
+	comment.AuthorID = stringPtr(authorID)
+	comment.CreatedAt = fromNanos(createdAt)
+	comment.EditedAt = timePtr(editedAt)
+	comment.DeletedAt = timePtr(deletedAt)
+	return comment, nil
+}
+
+func scanComments(rows *sql.Rows) ([]model.Comment, error) {
+	defer rows.Close()
+
+	var comments []model.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (cs *CommentStorage) SaveComment(ctx context.Context, c *model.Comment) (string, time.Time, error) {
+	const op = "storage.sqlite.SaveComment"
+
+	comment := &model.Comment{
+		ID:        uuid.New().String(),
+		PostID:    c.PostID,
+		ParentID:  c.ParentID,
+		AuthorID:  c.AuthorID,
+		Content:   c.Content,
+		CreatedAt: cs.s.now(),
+	}
+
+	// SQLite не сообщает, какой внешний ключ нарушен, поэтому пост и родитель проверяются явно
+	err := cs.s.withTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", comment.PostID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return apperr.ErrPostNotFound
+		}
+
+		if comment.ParentID != nil {
+			err := tx.QueryRowContext(ctx,
+				"SELECT EXISTS (SELECT 1 FROM comments WHERE id = ? AND post_id = ?)",
+				*comment.ParentID, comment.PostID).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return apperr.ErrParentNotFound
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO comments ("+commentColumns+") VALUES (?, ?, ?, ?, ?, ?, NULL, NULL)",
+			comment.ID, comment.PostID, comment.ParentID, comment.AuthorID, comment.Content, toNanos(comment.CreatedAt))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO post_stats (post_id, comments_count) VALUES (?, 1)
+			ON CONFLICT (post_id) DO UPDATE SET comments_count = post_stats.comments_count + 1`,
+			comment.PostID)
+		if err != nil {
+			return fmt.Errorf("failed to update comments count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comment.ID, comment.CreatedAt, nil
+}
+
+func (cs *CommentStorage) GetComments(ctx context.Context, first *int32, after *string, postID string) (*[]model.Comment, bool, string, error) {
+	const op = "storage.sqlite.GetComments"
+
+	comments, hasNextPage, endCursor, err := cs.getPage(ctx, first, after, "post_id = ?", postID)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, hasNextPage, endCursor, nil
+}
+
+// GetCommentsBatch возвращает первые страницы комментариев сразу для нескольких постов одним запросом
+func (cs *CommentStorage) GetCommentsBatch(ctx context.Context, first int32, postIDs []string) (map[string]*model.CommentsPage, error) {
+	const op = "storage.sqlite.GetCommentsBatch"
+
+	pages := make(map[string]*model.CommentsPage, len(postIDs))
+	for _, id := range postIDs {
+		pages[id] = &model.CommentsPage{}
+	}
+	if first == 0 || len(postIDs) == 0 {
+		return pages, nil
+	}
+
+	in, args := inArgs(postIDs)
+	rows, err := cs.s.DB.QueryContext(ctx, `
+		SELECT `+commentColumns+`
+		FROM (
+			SELECT c.*, row_number() OVER (PARTITION BY c.post_id ORDER BY c.created_at, c.id) AS rn
+			FROM comments AS c
+			WHERE c.post_id IN (`+in+`)
+		)
+		WHERE rn <= ?
+		ORDER BY post_id, created_at, id`,
+		append(args, first+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, c := range comments {
+		page := pages[c.PostID]
+		if len(page.Comments) == int(first) {
+			page.HasNextPage = true
+			continue
+		}
+		page.Comments = append(page.Comments, c)
+		page.EndCursor = c.ID
+	}
+
+	return pages, nil
+}
+
+// GetReplies возвращает страницу прямых ответов на комментарий parentID (корневые комментарии при parentID == nil)
+func (cs *CommentStorage) GetReplies(ctx context.Context, first *int32, after *string, postID string, parentID *string) (*[]model.Comment, bool, string, error) {
+	const op = "storage.sqlite.GetReplies"
+
+	var (
+		comments    *[]model.Comment
+		hasNextPage bool
+		endCursor   string
+		err         error
+	)
+	if parentID == nil {
+		comments, hasNextPage, endCursor, err = cs.getPage(ctx, first, after, "post_id = ? AND parent_id IS NULL", postID)
+	} else {
+		comments, hasNextPage, endCursor, err = cs.getPage(ctx, first, after, "post_id = ? AND parent_id = ?", postID, *parentID)
+	}
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, hasNextPage, endCursor, nil
+}
+
+// getPage выбирает страницу комментариев по ключу (created_at, id).
+// Курсор after должен удовлетворять тому же фильтру where, что и страница.
+func (cs *CommentStorage) getPage(ctx context.Context, first *int32, after *string, where string, args ...any) (*[]model.Comment, bool, string, error) {
+	var comments []model.Comment
+
+	if first == nil {
+		return nil, false, "", apperr.Validation("parameter `first` is missing")
+	} else if *first < 0 {
+		return nil, false, "", apperr.Validation("parameter `first` must not be negative")
+	} else if *first == 0 {
+		return &comments, false, "", nil
+	}
+
+	query := "SELECT " + commentColumns + " FROM comments WHERE " + where
+	if after != nil && *after != "" {
+		var createdAt int64
+		err := cs.s.DB.QueryRowContext(ctx,
+			"SELECT created_at FROM comments WHERE id = ? AND "+where,
+			append([]any{*after}, args...)...).Scan(&createdAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, false, "", apperr.ErrInvalidCursor
+			}
+			return nil, false, "", err
+		}
+		query += " AND (created_at, id) > (?, ?)"
+		args = append(args, createdAt, *after)
+	}
+	query += " ORDER BY created_at, id LIMIT ?"
+	args = append(args, int(*first)+1)
+
+	rows, err := cs.s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, "", err
+	}
+	comments, err = scanComments(rows)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	hasNextPage := false
+	if len(comments) == int(*first)+1 {
+		hasNextPage = true
+		comments = comments[:len(comments)-1]
+	}
+
+	var endCursor string
+	if len(comments) > 0 {
+		endCursor = comments[len(comments)-1].ID
+	}
+
+	return &comments, hasNextPage, endCursor, nil
+}
+
+// GetThread возвращает потомков комментариев rootIDs не глубже maxDepth уровней, по запросу на уровень.
+// На каждого родителя возвращается не больше limit+1 ответов: лишний ответ означает, что у родителя есть следующая страница.
+// Следующий уровень загружается только для первых limit ответов каждого родителя.
+func (cs *CommentStorage) GetThread(ctx context.Context, postID string, rootIDs []string, maxDepth int, limit int32) ([]model.Comment, error) {
+	const op = "storage.sqlite.GetThread"
+	var thread []model.Comment
+
+	level := rootIDs
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		in, args := inArgs(level)
+		rows, err := cs.s.DB.QueryContext(ctx, `
+			SELECT `+commentColumns+`
+			FROM (
+				SELECT c.*, row_number() OVER (PARTITION BY c.parent_id ORDER BY c.created_at, c.id) AS rn
+				FROM comments AS c
+				WHERE c.post_id = ? AND c.parent_id IN (`+in+`)
+			)
+			WHERE rn <= ?
+			ORDER BY parent_id, created_at, id`,
+			append(append([]any{postID}, args...), limit+1)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		replies, err := scanComments(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		thread = append(thread, replies...)
+		level = threadLevel(replies, limit)
+	}
+
+	return thread, nil
+}
+
+// threadLevel возвращает идентификаторы первых limit ответов каждого родителя.
+// Ответы должны быть сгруппированы по родителю и упорядочены по времени создания.
+func threadLevel(replies []model.Comment, limit int32) []string {
+	var ids []string
+	var parentID string
+	var n int32
+	for _, c := range replies {
+		if *c.ParentID != parentID {
+			parentID, n = *c.ParentID, 0
+		}
+		if n < limit {
+			ids = append(ids, c.ID)
+		}
+		n++
+	}
+	return ids
+}
+
+// CountComments читает счетчик из post_stats, который обновляется в одной транзакции с SaveComment и DeleteComment.
+// Удаленные комментарии в счетчик не входят.
+func (cs *CommentStorage) CountComments(ctx context.Context, postID string) (int32, error) {
+	const op = "storage.sqlite.CountComments"
+	var count int32
+
+	err := cs.s.DB.QueryRowContext(ctx,
+		"SELECT comments_count FROM post_stats WHERE post_id = ?", postID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func (cs *CommentStorage) IsCommentExist(ctx context.Context, commentID string, postID string) error {
+	const op = "storage.sqlite.IsCommentExist"
+	var exists bool
+
+	err := cs.s.DB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM comments WHERE id = ? AND post_id = ?)", commentID, postID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, apperr.ErrCommentNotFound)
+	}
+
+	return nil
+}
+
+func (cs *CommentStorage) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "storage.sqlite.GetComment"
+
+	comment, err := getComment(ctx, cs.s.DB, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+func getComment(ctx context.Context, q querier, id string) (*model.Comment, error) {
+	comment, err := scanComment(q.QueryRowContext(ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperr.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (cs *CommentStorage) UpdateComment(ctx context.Context, id string, content string) (*model.Comment, error) {
+	const op = "storage.sqlite.UpdateComment"
+
+	comment, err := cs.modify(ctx, id, func(tx *sql.Tx, comment *model.Comment) error {
+		comment.Content = content
+		editedAt := cs.s.now()
+		comment.EditedAt = &editedAt
+
+		_, err := tx.ExecContext(ctx,
+			"UPDATE comments SET content = ?, edited_at = ? WHERE id = ?",
+			comment.Content, toNanos(editedAt), id)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// комментарий не удаляется из таблицы, а превращается в "надгробие" без текста,
+// чтобы ответы на него сохранили свое место в ветке
+func (cs *CommentStorage) DeleteComment(ctx context.Context, id string) (*model.Comment, error) {
+	const op = "storage.sqlite.DeleteComment"
+
+	comment, err := cs.modify(ctx, id, func(tx *sql.Tx, comment *model.Comment) error {
+		comment.Content = ""
+		deletedAt := cs.s.now()
+		comment.DeletedAt = &deletedAt
+
+		_, err := tx.ExecContext(ctx,
+			"UPDATE comments SET content = '', deleted_at = ? WHERE id = ?",
+			toNanos(deletedAt), id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE post_stats SET comments_count = comments_count - 1 WHERE post_id = ?", comment.PostID)
+		if err != nil {
+			return fmt.Errorf("failed to update comments count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// modify читает комментарий и изменяет его в одной транзакции; удаленный комментарий не изменяется
+func (cs *CommentStorage) modify(ctx context.Context, id string, fn func(tx *sql.Tx, comment *model.Comment) error) (*model.Comment, error) {
+	var comment *model.Comment
+
+	err := cs.s.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		comment, err = getComment(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if comment.DeletedAt != nil {
+			return apperr.ErrCommentDeleted
+		}
+		return fn(tx, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"client-services/internal/storage/storagetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clock *storagetest.Clock) storagetest.Backend {
+		s := newTestStorage(t)
+		s.now = clock.Now
+		return storagetest.Backend{Posts: s.NewPostStorage(), Comments: s.NewCommentStorage()}
+	})
+}
@@ -0,0 +1,38 @@
+-- схема повторяет PostgreSQL после миграции 0007_constraints;
+-- время хранится в наносекундах Unix, чтобы сравнение по (created_at, id) совпадало с порядком создания
+CREATE TABLE users (
+	id text PRIMARY KEY,
+	username text NOT NULL UNIQUE,
+	role text NOT NULL DEFAULT 'USER',
+	password_hash text NOT NULL,
+	created_at integer NOT NULL
+);
+
+CREATE TABLE posts (
+	id text PRIMARY KEY,
+	author_id text REFERENCES users (id) ON DELETE SET NULL,
+	title text NOT NULL,
+	content text NOT NULL,
+	comments_allowed integer NOT NULL DEFAULT 0,
+	private integer NOT NULL DEFAULT 0,
+	created_at integer NOT NULL,
+	edited_at integer
+);
+
+CREATE TABLE comments (
+	id text PRIMARY KEY,
+	post_id text NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+	parent_id text,
+	author_id text REFERENCES users (id) ON DELETE SET NULL,
+	content text NOT NULL,
+	created_at integer NOT NULL,
+	edited_at integer,
+	deleted_at integer,
+	-- родительский комментарий должен принадлежать тому же посту
+	UNIQUE (post_id, id),
+	FOREIGN KEY (post_id, parent_id) REFERENCES comments (post_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX posts_created_at_id_idx ON posts (created_at, id);
+CREATE INDEX comments_post_id_created_at_id_idx ON comments (post_id, created_at, id);
+CREATE INDEX comments_post_id_parent_id_created_at_id_idx ON comments (post_id, parent_id, created_at, id);
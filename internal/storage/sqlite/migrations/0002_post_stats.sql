@@ -0,0 +1,9 @@
+-- счетчик неудаленных комментариев, как post_stats в PostgreSQL;
+-- обновляется в одной транзакции с SaveComment и DeleteComment
+CREATE TABLE post_stats (
+	post_id text PRIMARY KEY REFERENCES posts (id) ON DELETE CASCADE,
+	comments_count integer NOT NULL DEFAULT 0
+);
+
+INSERT INTO post_stats (post_id, comments_count)
+SELECT post_id, count(*) FROM comments WHERE deleted_at IS NULL GROUP BY post_id;
@@ -0,0 +1,245 @@
+package sqlite
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const postColumns = "id, author_id, title, content, comments_allowed, private, created_at, edited_at"
+
+type PostStorage struct {
+	s *Storage
+}
+
+func (s *Storage) NewPostStorage() *PostStorage {
+	return &PostStorage{s: s}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPost(row scanner) (model.Post, error) {
+	var (
+		post      model.Post
+		authorID  sql.NullString
+		createdAt int64
+		editedAt  sql.NullInt64
+	)
+
+	err := row.Scan(&post.ID, &authorID, &post.Title, &post.Content,
+		&post.CommentsAllowed, &post.Private, &createdAt, &editedAt)
+	if err != nil {
+		return model.Post{}, err
+	}
+
+	post.AuthorID = stringPtr(authorID)
+	post.CreatedAt = fromNanos(createdAt)
+	post.EditedAt = timePtr(editedAt)
+	return post, nil
+}
+
+func scanPosts(rows *sql.Rows) ([]model.Post, error) {
+	defer rows.Close()
+
+	var posts []model.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+func (ps *PostStorage) SavePost(ctx context.Context, p *model.Post) (string, time.Time, error) {
+	const op = "storage.sqlite.SavePost"
+
+	post := &model.Post{
+		ID:              uuid.New().String(),
+		AuthorID:        p.AuthorID,
+		Title:           p.Title,
+		Content:         p.Content,
+		CommentsAllowed: p.CommentsAllowed,
+		Private:         p.Private,
+		CreatedAt:       ps.s.now(),
+	}
+
+	_, err := ps.s.DB.ExecContext(ctx,
+		"INSERT INTO posts ("+postColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, NULL)",
+		post.ID, post.AuthorID, post.Title, post.Content, post.CommentsAllowed, post.Private, toNanos(post.CreatedAt))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: failed to insert post: %w", op, err)
+	}
+
+	return post.ID, post.CreatedAt, nil
+}
+
+func (ps *PostStorage) GetPost(ctx context.Context, id string) (*model.Post, error) {
+	const op = "storage.sqlite.GetPost"
+
+	post, err := ps.getPost(ctx, ps.s.DB, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (ps *PostStorage) getPost(ctx context.Context, q querier, id string) (*model.Post, error) {
+	post, err := scanPost(q.QueryRowContext(ctx,
+		"SELECT "+postColumns+" FROM posts WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperr.ErrPostNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (ps *PostStorage) GetAllPosts(ctx context.Context) ([]model.Post, error) {
+	const op = "storage.sqlite.GetAllPosts"
+
+	rows, err := ps.s.DB.QueryContext(ctx,
+		"SELECT "+postColumns+" FROM posts ORDER BY created_at, id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	posts, err := scanPosts(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return posts, nil
+}
+
+// GetPosts выбирает страницу постов по ключу (created_at, id)
+func (ps *PostStorage) GetPosts(ctx context.Context, first *int32, after *string, desc bool) (*[]model.Post, bool, string, error) {
+	const op = "storage.sqlite.GetPosts"
+	var posts []model.Post
+
+	if first == nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` is missing"))
+	} else if *first < 0 {
+		return nil, false, "", fmt.Errorf("%s: %w", op, apperr.Validation("parameter `first` must not be negative"))
+	} else if *first == 0 {
+		return &posts, false, "", nil
+	}
+
+	order, compare := "ASC", ">"
+	if desc {
+		order, compare = "DESC", "<"
+	}
+
+	query := "SELECT " + postColumns + " FROM posts"
+	args := []any{}
+	if after != nil && *after != "" {
+		cursor, err := ps.getPost(ctx, ps.s.DB, *after)
+		if err != nil {
+			if errors.Is(err, apperr.ErrPostNotFound) {
+				return nil, false, "", fmt.Errorf("%s: %w", op, apperr.ErrInvalidCursor)
+			}
+			return nil, false, "", fmt.Errorf("%s: %w", op, err)
+		}
+		query += " WHERE (created_at, id) " + compare + " (?, ?)"
+		args = append(args, toNanos(cursor.CreatedAt), cursor.ID)
+	}
+	query += " ORDER BY created_at " + order + ", id " + order + " LIMIT ?"
+	args = append(args, int(*first)+1)
+
+	rows, err := ps.s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+	posts, err = scanPosts(rows)
+	if err != nil {
+		return nil, false, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	hasNextPage := false
+	if len(posts) == int(*first)+1 {
+		hasNextPage = true
+		posts = posts[:len(posts)-1]
+	}
+
+	var endCursor string
+	if len(posts) > 0 {
+		endCursor = posts[len(posts)-1].ID
+	}
+
+	return &posts, hasNextPage, endCursor, nil
+}
+
+func (ps *PostStorage) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	const op = "storage.sqlite.UpdatePost"
+	var post *model.Post
+
+	err := ps.s.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		post, err = ps.getPost(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if title != nil {
+			post.Title = *title
+		}
+		if content != nil {
+			post.Content = *content
+		}
+		editedAt := ps.s.now()
+		post.EditedAt = &editedAt
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE posts SET title = ?, content = ?, edited_at = ? WHERE id = ?",
+			post.Title, post.Content, toNanos(editedAt), id)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (ps *PostStorage) DeletePost(ctx context.Context, id string) error {
+	const op = "storage.sqlite.DeletePost"
+
+	// комментарии удаляются каскадно внешними ключами
+	res, err := ps.s.DB.ExecContext(ctx, "DELETE FROM posts WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete post: %w", op, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	} else if n == 0 {
+		return fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+	}
+
+	return nil
+}
+
+func (ps *PostStorage) SetCommentsAllowed(ctx context.Context, id string, allowed bool) (*model.Post, error) {
+	const op = "storage.sqlite.SetCommentsAllowed"
+
+	post, err := scanPost(ps.s.DB.QueryRowContext(ctx,
+		"UPDATE posts SET comments_allowed = ? WHERE id = ? RETURNING "+postColumns, allowed, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, apperr.ErrPostNotFound)
+		}
+		return nil, fmt.Errorf("%s: failed to update post: %w", op, err)
+	}
+
+	return &post, nil
+}
@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"client-services/internal/config"
+	"client-services/internal/storage"
+)
+
+func init() {
+	storage.Register("sqlite", func(cfg *config.Config, _ storage.Deps) (*storage.Backend, error) {
+		s, err := NewStorage(&cfg.SQLite)
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage.Backend{
+			Storage:  s,
+			Posts:    s.NewPostStorage(),
+			Comments: s.NewCommentStorage(),
+			Users:    s.NewUserStorage(),
+		}, nil
+	})
+}
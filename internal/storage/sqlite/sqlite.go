@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"client-services/internal/config"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+type Storage struct {
+	DB *sql.DB
+	// источник времени создания и изменения постов и комментариев; подменяется в тестах
+	now func() time.Time
+}
+
+// NewStorage открывает файл базы SQLite и применяет недостающие миграции
+func NewStorage(cfg *config.SQLite) (*Storage, error) {
+	const op = "storage.sqlite.NewStorage"
+
+	if cfg == nil {
+		return nil, fmt.Errorf("%s: sqlite section is missing in the config file", op)
+	}
+	// пустой путь дал бы DSN "file:?", то есть отдельную временную базу у каждого соединения
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("%s: sqlite path is not set", op)
+	}
+	if dir := filepath.Dir(cfg.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// транзакции сразу берут блокировку записи: при BEGIN DEFERRED повышение блокировки
+	// внутри транзакции завершается ошибкой SQLITE_BUSY без ожидания busy_timeout
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	params.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+cfg.Path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s := &Storage{DB: db, now: time.Now}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: failed to migrate: %w", op, err)
+	}
+
+	return s, nil
+}
+
+func (s *Storage) CloseDB() error {
+	return s.DB.Close()
+}
+
+// migrate применяет встроенные миграции NNNN_name.sql, которых еще нет в schema_migrations
+func (s *Storage) migrate(ctx context.Context) error {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+
+	type migration struct {
+		version int64
+		name    string
+		file    string
+	}
+	migrations := make([]migration, 0, len(files))
+	for _, file := range files {
+		parts := migrationName.FindStringSubmatch(path.Base(file))
+		if parts == nil {
+			return fmt.Errorf("invalid migration file name %q", file)
+		}
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version in %q: %w", file, err)
+		}
+		migrations = append(migrations, migration{version: version, name: parts[2], file: file})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	_, err = s.DB.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version integer PRIMARY KEY,
+			name text NOT NULL,
+			applied_at integer NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		body, err := fs.ReadFile(migrationsFS, m.file)
+		if err != nil {
+			return err
+		}
+
+		err = s.withTx(ctx, func(tx *sql.Tx) error {
+			var applied bool
+			err := tx.QueryRowContext(ctx,
+				"SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)", m.version).Scan(&applied)
+			if err != nil || applied {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				m.version, m.name, toNanos(time.Now()))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// withTx выполняет fn в транзакции; транзакция откатывается, если fn вернула ошибку
+func (s *Storage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func toNanos(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+func fromNanos(n int64) time.Time {
+	return time.Unix(0, n)
+}
+
+func nullTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: toNanos(*t), Valid: true}
+}
+
+func timePtr(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+	t := fromNanos(n.Int64)
+	return &t
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// inArgs возвращает список плейсхолдеров "?, ?, ..." и аргументы для условия IN
+func inArgs(values []string) (string, []any) {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+// querier - общая часть *sql.DB и *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/config"
+	"client-services/internal/graph/model"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	s, err := NewStorage(&config.SQLite{Path: filepath.Join(t.TempDir(), "test.db"), BusyTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { s.CloseDB() })
+	return s
+}
+
+func TestStorage_Comments(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+	ps, cs := s.NewPostStorage(), s.NewCommentStorage()
+
+	postID, createdAt, err := ps.SavePost(ctx, &model.Post{Title: "title", Content: "content", CommentsAllowed: true})
+	require.NoError(t, err)
+
+	post, err := ps.GetPost(ctx, postID)
+	require.NoError(t, err)
+	require.True(t, post.CommentsAllowed)
+	require.True(t, createdAt.Equal(post.CreatedAt))
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _, err := cs.SaveComment(ctx, &model.Comment{PostID: postID, Content: "comment"})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	replyID, _, err := cs.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: &ids[0], Content: "reply"})
+	require.NoError(t, err)
+
+	first := int32(2)
+	page, hasNext, endCursor, err := cs.GetComments(ctx, &first, &ids[0], postID)
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, ids[2], endCursor)
+	require.Len(t, *page, 2)
+
+	page, _, _, err = cs.GetReplies(ctx, &first, nil, postID, &ids[0])
+	require.NoError(t, err)
+	require.Len(t, *page, 1)
+	require.Equal(t, replyID, (*page)[0].ID)
+
+	thread, err := cs.GetThread(ctx, postID, []string{ids[0]}, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, thread, 1)
+
+	_, _, _, err = cs.GetReplies(ctx, &first, &replyID, postID, nil)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+
+	_, _, err = cs.SaveComment(ctx, &model.Comment{PostID: "missing", Content: "comment"})
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	missing := "missing"
+	_, _, err = cs.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: &missing, Content: "comment"})
+	require.ErrorIs(t, err, apperr.ErrParentNotFound)
+
+	deleted, err := cs.DeleteComment(ctx, ids[1])
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	_, err = cs.UpdateComment(ctx, ids[1], "edited")
+	require.ErrorIs(t, err, apperr.ErrCommentDeleted)
+
+	// счетчик хранится в post_stats и уменьшается при удалении комментария
+	count, err := cs.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), count)
+
+	// комментарии и счетчик удаляются вместе с постом
+	require.NoError(t, ps.DeletePost(ctx, postID))
+	_, err = cs.GetComment(ctx, replyID)
+	require.ErrorIs(t, err, apperr.ErrCommentNotFound)
+	var stats int
+	require.NoError(t, s.DB.QueryRowContext(ctx, "SELECT count(*) FROM post_stats WHERE post_id = ?", postID).Scan(&stats))
+	require.Zero(t, stats)
+	require.ErrorIs(t, ps.DeletePost(ctx, postID), apperr.ErrPostNotFound)
+}
+
+func TestStorage_Users(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+	us := s.NewUserStorage()
+
+	id, _, err := us.SaveUser(ctx, &model.User{Username: "user", Role: model.RoleUser}, "hash")
+	require.NoError(t, err)
+	_, _, err = us.SaveUser(ctx, &model.User{Username: "user", Role: model.RoleUser}, "hash")
+	require.ErrorIs(t, err, apperr.ErrUserExists)
+
+	user, err := us.SetUserRole(ctx, id, model.RoleAdmin)
+	require.NoError(t, err)
+	require.Equal(t, model.RoleAdmin, user.Role)
+
+	user, hash, err := us.GetUserByName(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, "hash", hash)
+	require.Equal(t, model.RoleAdmin, user.Role)
+
+	// автор сохраняется у поста
+	postID, _, err := s.NewPostStorage().SavePost(ctx, &model.Post{AuthorID: &id, Title: "title", Content: "content"})
+	require.NoError(t, err)
+	post, err := s.NewPostStorage().GetPost(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, id, *post.AuthorID)
+
+	_, err = us.SetUserRole(ctx, "missing", model.RoleAdmin)
+	require.ErrorIs(t, err, apperr.ErrUserNotFound)
+}
+
+func TestStorage_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	cfg := &config.SQLite{Path: path, BusyTimeout: 5 * time.Second}
+
+	s, err := NewStorage(cfg)
+	require.NoError(t, err)
+	postID, _, err := s.NewPostStorage().SavePost(ctx, &model.Post{Title: "title", Content: "content"})
+	require.NoError(t, err)
+	require.NoError(t, s.CloseDB())
+
+	// повторное открытие не применяет миграции заново
+	s, err = NewStorage(cfg)
+	require.NoError(t, err)
+	defer s.CloseDB()
+	_, err = s.NewPostStorage().GetPost(ctx, postID)
+	require.NoError(t, err)
+}
+
+func TestNewStorage_MissingConfig(t *testing.T) {
+	_, err := NewStorage(nil)
+	require.ErrorContains(t, err, "sqlite section is missing")
+	_, err = NewStorage(&config.SQLite{BusyTimeout: 5 * time.Second})
+	require.ErrorContains(t, err, "sqlite path is not set")
+}
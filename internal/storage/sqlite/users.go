@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type UserStorage struct {
+	s *Storage
+}
+
+func (s *Storage) NewUserStorage() *UserStorage {
+	return &UserStorage{s: s}
+}
+
+func scanUser(row scanner) (*model.User, string, error) {
+	var (
+		user         model.User
+		passwordHash string
+		createdAt    int64
+	)
+
+	if err := row.Scan(&user.ID, &user.Username, &user.Role, &passwordHash, &createdAt); err != nil {
+		return nil, "", err
+	}
+
+	user.CreatedAt = fromNanos(createdAt)
+	return &user, passwordHash, nil
+}
+
+func (us *UserStorage) SaveUser(ctx context.Context, u *model.User, passwordHash string) (string, time.Time, error) {
+	const op = "storage.sqlite.SaveUser"
+
+	id := uuid.New().String()
+	createdAt := time.Now()
+
+	_, err := us.s.DB.ExecContext(ctx,
+		"INSERT INTO users (id, username, role, password_hash, created_at) VALUES (?, ?, ?, ?, ?)",
+		id, u.Username, u.Role.String(), passwordHash, toNanos(createdAt))
+	if err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+			return "", time.Time{}, fmt.Errorf("%s: %w", op, apperr.ErrUserExists)
+		}
+		return "", time.Time{}, fmt.Errorf("%s: failed to insert user: %w", op, err)
+	}
+
+	return id, createdAt, nil
+}
+
+func (us *UserStorage) GetUserByName(ctx context.Context, username string) (*model.User, string, error) {
+	const op = "storage.sqlite.GetUserByName"
+
+	user, passwordHash, err := scanUser(us.s.DB.QueryRowContext(ctx,
+		"SELECT id, username, role, password_hash, created_at FROM users WHERE username = ?", username))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+		}
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, passwordHash, nil
+}
+
+func (us *UserStorage) GetUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	const op = "storage.sqlite.GetUsers"
+
+	users := make(map[string]*model.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	in, args := inArgs(ids)
+	rows, err := us.s.DB.QueryContext(ctx,
+		"SELECT id, username, role, password_hash, created_at FROM users WHERE id IN ("+in+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, _, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users[user.ID] = user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (us *UserStorage) SetUserRole(ctx context.Context, id string, role model.Role) (*model.User, error) {
+	const op = "storage.sqlite.SetUserRole"
+
+	user, _, err := scanUser(us.s.DB.QueryRowContext(ctx,
+		"UPDATE users SET role = ? WHERE id = ? RETURNING id, username, role, password_hash, created_at",
+		role.String(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, apperr.ErrUserNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,658 @@
+// Package storagetest - общий набор проверок поведения хранилищ постов и комментариев.
+// Каждое хранилище запускает его из своих тестов, поэтому все реализации одинаково
+// обрабатывают пагинацию, курсоры, проверку родителя и конкурентную запись.
+package storagetest
+
+import (
+	"client-services/internal/apperr"
+	"client-services/internal/graph"
+	"client-services/internal/graph/model"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Backend - проверяемое хранилище
+type Backend struct {
+	Posts    graph.PostInterface
+	Comments graph.CommentInterface
+}
+
+// Factory создает пустое хранилище, которое берет время создания и изменения из clock
+type Factory func(t *testing.T, clock *Clock) Backend
+
+// Clock - управляемые часы: каждый вызов Now сдвигает время на шаг.
+// Шаг в миллисекунду сохраняет порядок и в PostgreSQL, который хранит время с точностью до микросекунды.
+type Clock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func NewClock() *Clock {
+	return &Clock{
+		now:  time.Now().Truncate(time.Second),
+		step: time.Millisecond,
+	}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Freeze останавливает часы: все следующие объекты получают одинаковое время создания
+func (c *Clock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.step = 0
+}
+
+// Run запускает все проверки для хранилища, создаваемого newBackend
+func Run(t *testing.T, newBackend Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, b Backend, clock *Clock)
+	}{
+		{"PostsPagination", testPostsPagination},
+		{"CommentsPagination", testCommentsPagination},
+		{"CommentsFirstZero", testCommentsFirstZero},
+		{"InvalidCursor", testInvalidCursor},
+		{"EqualCreatedAt", testEqualCreatedAt},
+		{"Replies", testReplies},
+		{"ParentValidation", testParentValidation},
+		{"UpdateDelete", testUpdateDelete},
+		{"DeletePost", testDeletePost},
+		{"CommentsBatch", testCommentsBatch},
+		{"ConcurrentWrites", testConcurrentWrites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			tt.fn(t, newBackend(t, clock), clock)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func savePost(t *testing.T, b Backend) string {
+	t.Helper()
+
+	id, _, err := b.Posts.SavePost(context.Background(), &model.Post{Title: "title", Content: "content", CommentsAllowed: true})
+	require.NoError(t, err)
+	return id
+}
+
+func saveComment(t *testing.T, b Backend, postID string, parentID *string) string {
+	t.Helper()
+
+	id, _, err := b.Comments.SaveComment(context.Background(), &model.Comment{PostID: postID, ParentID: parentID, Content: "comment"})
+	require.NoError(t, err)
+	return id
+}
+
+func postIDs(posts []model.Post) []string {
+	ids := make([]string, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func commentIDs(comments []model.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// pageAll проходит все страницы по first элементов и возвращает ID в порядке выдачи
+func pageAll(t *testing.T, first int32, page func(after *string) ([]string, bool, string, error)) []string {
+	t.Helper()
+
+	var all []string
+	var after *string
+	for i := 0; ; i++ {
+		require.Less(t, i, 1000, "pagination does not terminate")
+
+		ids, hasNext, endCursor, err := page(after)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(ids), int(first))
+		all = append(all, ids...)
+		if len(ids) > 0 {
+			require.Equal(t, ids[len(ids)-1], endCursor)
+		}
+		if !hasNext {
+			return all
+		}
+		require.Len(t, ids, int(first), "only the last page may be shorter than first")
+		after = ptr(endCursor)
+	}
+}
+
+func testPostsPagination(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, savePost(t, b))
+	}
+
+	all, err := b.Posts.GetAllPosts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ids, postIDs(all))
+
+	for _, desc := range []bool{false, true} {
+		got := pageAll(t, 2, func(after *string) ([]string, bool, string, error) {
+			posts, hasNext, endCursor, err := b.Posts.GetPosts(ctx, ptr(int32(2)), after, desc)
+			if err != nil {
+				return nil, false, "", err
+			}
+			return postIDs(*posts), hasNext, endCursor, nil
+		})
+
+		want := ids
+		if desc {
+			want = []string{ids[4], ids[3], ids[2], ids[1], ids[0]}
+		}
+		require.Equal(t, want, got)
+	}
+
+	// страница ровно до последнего поста не сообщает о следующей
+	posts, hasNext, _, err := b.Posts.GetPosts(ctx, ptr(int32(2)), &ids[2], false)
+	require.NoError(t, err)
+	require.Equal(t, ids[3:], postIDs(*posts))
+	require.False(t, hasNext)
+
+	posts, hasNext, endCursor, err := b.Posts.GetPosts(ctx, ptr(int32(0)), nil, false)
+	require.NoError(t, err)
+	require.Empty(t, *posts)
+	require.False(t, hasNext)
+	require.Empty(t, endCursor)
+
+	_, _, _, err = b.Posts.GetPosts(ctx, nil, nil, false)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+	_, _, _, err = b.Posts.GetPosts(ctx, ptr(int32(-1)), nil, false)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+	_, _, _, err = b.Posts.GetPosts(ctx, ptr(int32(2)), ptr("missing"), false)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+}
+
+func testCommentsPagination(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	otherID := savePost(t, b)
+
+	// у поста без комментариев пустая страница
+	comments, hasNext, endCursor, err := b.Comments.GetComments(ctx, ptr(int32(10)), nil, postID)
+	require.NoError(t, err)
+	require.Empty(t, *comments)
+	require.False(t, hasNext)
+	require.Empty(t, endCursor)
+
+	var ids []string
+	for i := 0; i < 7; i++ {
+		ids = append(ids, saveComment(t, b, postID, nil))
+		// комментарии другого поста не попадают в выдачу
+		saveComment(t, b, otherID, nil)
+	}
+	// ответы выдаются вместе с корневыми комментариями в порядке создания
+	ids = append(ids, saveComment(t, b, postID, &ids[0]))
+
+	for _, first := range []int32{1, 3, 8, 20} {
+		got := pageAll(t, first, func(after *string) ([]string, bool, string, error) {
+			comments, hasNext, endCursor, err := b.Comments.GetComments(ctx, &first, after, postID)
+			if err != nil {
+				return nil, false, "", err
+			}
+			return commentIDs(*comments), hasNext, endCursor, nil
+		})
+		require.Equal(t, ids, got, "first = %d", first)
+	}
+
+	// курсор на последнем комментарии
+	comments, hasNext, _, err = b.Comments.GetComments(ctx, ptr(int32(5)), &ids[len(ids)-1], postID)
+	require.NoError(t, err)
+	require.Empty(t, *comments)
+	require.False(t, hasNext)
+
+	count, err := b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(len(ids)), count)
+
+	count, err = b.Comments.CountComments(ctx, "missing")
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func testCommentsFirstZero(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	commentID := saveComment(t, b, postID, nil)
+
+	// first == 0 возвращает пустую страницу без проверки курсора
+	for _, after := range []*string{nil, &commentID, ptr("missing")} {
+		comments, hasNext, endCursor, err := b.Comments.GetComments(ctx, ptr(int32(0)), after, postID)
+		require.NoError(t, err)
+		require.NotNil(t, comments)
+		require.Empty(t, *comments)
+		require.False(t, hasNext)
+		require.Empty(t, endCursor)
+
+		replies, hasNext, endCursor, err := b.Comments.GetReplies(ctx, ptr(int32(0)), after, postID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, replies)
+		require.Empty(t, *replies)
+		require.False(t, hasNext)
+		require.Empty(t, endCursor)
+	}
+
+	_, _, _, err := b.Comments.GetComments(ctx, nil, nil, postID)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+	_, _, _, err = b.Comments.GetComments(ctx, ptr(int32(-1)), nil, postID)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+	_, _, _, err = b.Comments.GetReplies(ctx, nil, nil, postID, nil)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+	_, _, _, err = b.Comments.GetReplies(ctx, ptr(int32(-1)), nil, postID, nil)
+	require.ErrorIs(t, err, apperr.ErrValidation)
+}
+
+func testInvalidCursor(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	otherID := savePost(t, b)
+
+	rootID := saveComment(t, b, postID, nil)
+	replyID := saveComment(t, b, postID, &rootID)
+	otherCommentID := saveComment(t, b, otherID, nil)
+
+	first := ptr(int32(10))
+	cursors := []struct {
+		name   string
+		after  string
+		parent *string
+	}{
+		{"unknown comment", "missing", nil},
+		{"comment of another post", otherCommentID, nil},
+		{"reply as root cursor", replyID, nil},
+		{"root as reply cursor", rootID, &rootID},
+	}
+
+	for _, c := range cursors {
+		_, _, _, err := b.Comments.GetReplies(ctx, first, &c.after, postID, c.parent)
+		require.ErrorIs(t, err, apperr.ErrInvalidCursor, c.name)
+		require.ErrorIs(t, err, apperr.ErrValidation, c.name)
+	}
+
+	_, _, _, err := b.Comments.GetComments(ctx, first, ptr("missing"), postID)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+	_, _, _, err = b.Comments.GetComments(ctx, first, &otherCommentID, postID)
+	require.ErrorIs(t, err, apperr.ErrInvalidCursor)
+
+	// пустой курсор означает начало списка
+	comments, _, _, err := b.Comments.GetComments(ctx, first, ptr(""), postID)
+	require.NoError(t, err)
+	require.Equal(t, []string{rootID, replyID}, commentIDs(*comments))
+}
+
+func testEqualCreatedAt(t *testing.T, b Backend, clock *Clock) {
+	ctx := context.Background()
+	clock.Freeze()
+
+	var posts []string
+	for i := 0; i < 4; i++ {
+		posts = append(posts, savePost(t, b))
+	}
+	postID := posts[0]
+
+	var ids []string
+	for i := 0; i < 9; i++ {
+		ids = append(ids, saveComment(t, b, postID, nil))
+	}
+
+	// при одинаковом времени создания порядок определяется ID, и страницы не теряют и не повторяют элементы
+	got := pageAll(t, 2, func(after *string) ([]string, bool, string, error) {
+		comments, hasNext, endCursor, err := b.Comments.GetComments(ctx, ptr(int32(2)), after, postID)
+		if err != nil {
+			return nil, false, "", err
+		}
+		return commentIDs(*comments), hasNext, endCursor, nil
+	})
+	require.ElementsMatch(t, ids, got)
+	require.IsIncreasing(t, got)
+
+	got = pageAll(t, 4, func(after *string) ([]string, bool, string, error) {
+		replies, hasNext, endCursor, err := b.Comments.GetReplies(ctx, ptr(int32(4)), after, postID, nil)
+		if err != nil {
+			return nil, false, "", err
+		}
+		return commentIDs(*replies), hasNext, endCursor, nil
+	})
+	require.ElementsMatch(t, ids, got)
+	require.IsIncreasing(t, got)
+
+	got = pageAll(t, 3, func(after *string) ([]string, bool, string, error) {
+		page, hasNext, endCursor, err := b.Posts.GetPosts(ctx, ptr(int32(3)), after, false)
+		if err != nil {
+			return nil, false, "", err
+		}
+		return postIDs(*page), hasNext, endCursor, nil
+	})
+	require.ElementsMatch(t, posts, got)
+	require.IsIncreasing(t, got)
+
+	batch, err := b.Comments.GetCommentsBatch(ctx, 3, []string{postID})
+	require.NoError(t, err)
+	require.True(t, batch[postID].HasNextPage)
+	require.IsIncreasing(t, commentIDs(batch[postID].Comments))
+}
+
+func testReplies(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+
+	root := saveComment(t, b, postID, nil)
+	other := saveComment(t, b, postID, nil)
+	reply1 := saveComment(t, b, postID, &root)
+	reply2 := saveComment(t, b, postID, &root)
+	nested := saveComment(t, b, postID, &reply1)
+	saveComment(t, b, postID, &nested)
+	lateReply := saveComment(t, b, postID, &reply2)
+
+	roots, hasNext, _, err := b.Comments.GetReplies(ctx, ptr(int32(10)), nil, postID, nil)
+	require.NoError(t, err)
+	require.False(t, hasNext)
+	require.Equal(t, []string{root, other}, commentIDs(*roots))
+
+	replies, hasNext, endCursor, err := b.Comments.GetReplies(ctx, ptr(int32(1)), nil, postID, &root)
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, []string{reply1}, commentIDs(*replies))
+
+	replies, hasNext, _, err = b.Comments.GetReplies(ctx, ptr(int32(1)), &endCursor, postID, &root)
+	require.NoError(t, err)
+	require.False(t, hasNext)
+	require.Equal(t, []string{reply2}, commentIDs(*replies))
+
+	// ответы без собственных ответов
+	replies, _, _, err = b.Comments.GetReplies(ctx, ptr(int32(10)), nil, postID, &other)
+	require.NoError(t, err)
+	require.Empty(t, *replies)
+
+	// потомки не глубже maxDepth, ответы одному родителю - в порядке создания
+	thread, err := b.Comments.GetThread(ctx, postID, []string{root}, 2, 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{reply1, reply2, nested, lateReply}, commentIDs(thread))
+	var rootReplies []string
+	for _, c := range thread {
+		if *c.ParentID == root {
+			rootReplies = append(rootReplies, c.ID)
+		}
+	}
+	require.Equal(t, []string{reply1, reply2}, rootReplies)
+
+	// не больше limit+1 ответов на родителя, следующий уровень - только для первых limit
+	thread, err = b.Comments.GetThread(ctx, postID, []string{root}, 2, 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{reply1, reply2, nested}, commentIDs(thread))
+
+	thread, err = b.Comments.GetThread(ctx, postID, []string{root}, 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, thread)
+	thread, err = b.Comments.GetThread(ctx, postID, nil, 3, 10)
+	require.NoError(t, err)
+	require.Empty(t, thread)
+}
+
+func testParentValidation(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	otherID := savePost(t, b)
+	otherCommentID := saveComment(t, b, otherID, nil)
+
+	_, _, err := b.Comments.SaveComment(ctx, &model.Comment{PostID: "missing", Content: "comment"})
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	require.ErrorIs(t, err, apperr.ErrNotFound)
+
+	_, _, err = b.Comments.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: ptr("missing"), Content: "comment"})
+	require.ErrorIs(t, err, apperr.ErrParentNotFound)
+
+	// родитель из другого поста
+	_, _, err = b.Comments.SaveComment(ctx, &model.Comment{PostID: postID, ParentID: &otherCommentID, Content: "comment"})
+	require.ErrorIs(t, err, apperr.ErrParentNotFound)
+
+	// ответ на удаленный комментарий допустим: надгробие остается в ветке
+	rootID := saveComment(t, b, postID, nil)
+	_, err = b.Comments.DeleteComment(ctx, rootID)
+	require.NoError(t, err)
+	saveComment(t, b, postID, &rootID)
+
+	// удаленный комментарий остается в ветке, но не входит в счетчик
+	count, err := b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), count)
+
+	require.NoError(t, b.Comments.IsCommentExist(ctx, rootID, postID))
+	require.ErrorIs(t, b.Comments.IsCommentExist(ctx, rootID, otherID), apperr.ErrCommentNotFound)
+	require.ErrorIs(t, b.Comments.IsCommentExist(ctx, "missing", postID), apperr.ErrCommentNotFound)
+}
+
+func testUpdateDelete(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	commentID := saveComment(t, b, postID, nil)
+
+	post, err := b.Posts.UpdatePost(ctx, postID, ptr("new title"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "new title", post.Title)
+	require.Equal(t, "content", post.Content)
+	require.NotNil(t, post.EditedAt)
+
+	post, err = b.Posts.SetCommentsAllowed(ctx, postID, false)
+	require.NoError(t, err)
+	require.False(t, post.CommentsAllowed)
+	require.Equal(t, "new title", post.Title)
+
+	stored, err := b.Posts.GetPost(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, "new title", stored.Title)
+	require.False(t, stored.CommentsAllowed)
+
+	comment, err := b.Comments.UpdateComment(ctx, commentID, "edited")
+	require.NoError(t, err)
+	require.Equal(t, "edited", comment.Content)
+	require.NotNil(t, comment.EditedAt)
+
+	otherID := saveComment(t, b, postID, nil)
+	count, err := b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), count)
+
+	comment, err = b.Comments.DeleteComment(ctx, commentID)
+	require.NoError(t, err)
+	require.Empty(t, comment.Content)
+	require.NotNil(t, comment.DeletedAt)
+
+	// счетчик совпадает с числом неудаленных комментариев, которые возвращает пагинация
+	count, err = b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), count)
+	comments, _, _, err := b.Comments.GetComments(ctx, ptr(int32(10)), nil, postID)
+	require.NoError(t, err)
+	var live []string
+	for _, c := range *comments {
+		if c.DeletedAt == nil {
+			live = append(live, c.ID)
+		}
+	}
+	require.Equal(t, []string{otherID}, live)
+
+	stored2, err := b.Comments.GetComment(ctx, commentID)
+	require.NoError(t, err)
+	require.Empty(t, stored2.Content)
+	require.NotNil(t, stored2.DeletedAt)
+
+	_, err = b.Comments.UpdateComment(ctx, commentID, "again")
+	require.ErrorIs(t, err, apperr.ErrCommentDeleted)
+	_, err = b.Comments.DeleteComment(ctx, commentID)
+	require.ErrorIs(t, err, apperr.ErrCommentDeleted)
+
+	_, err = b.Comments.UpdateComment(ctx, "missing", "content")
+	require.ErrorIs(t, err, apperr.ErrCommentNotFound)
+	_, err = b.Comments.GetComment(ctx, "missing")
+	require.ErrorIs(t, err, apperr.ErrCommentNotFound)
+	_, err = b.Posts.UpdatePost(ctx, "missing", ptr("title"), nil)
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	_, err = b.Posts.SetCommentsAllowed(ctx, "missing", true)
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	_, err = b.Posts.GetPost(ctx, "missing")
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+}
+
+func testDeletePost(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	otherID := savePost(t, b)
+
+	rootID := saveComment(t, b, postID, nil)
+	replyID := saveComment(t, b, postID, &rootID)
+	otherCommentID := saveComment(t, b, otherID, nil)
+
+	require.NoError(t, b.Posts.DeletePost(ctx, postID))
+
+	_, err := b.Posts.GetPost(ctx, postID)
+	require.ErrorIs(t, err, apperr.ErrPostNotFound)
+	_, err = b.Comments.GetComment(ctx, replyID)
+	require.ErrorIs(t, err, apperr.ErrCommentNotFound)
+
+	count, err := b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	comments, _, _, err := b.Comments.GetComments(ctx, ptr(int32(10)), nil, postID)
+	require.NoError(t, err)
+	require.Empty(t, *comments)
+
+	// другие посты не затронуты
+	comments, _, _, err = b.Comments.GetComments(ctx, ptr(int32(10)), nil, otherID)
+	require.NoError(t, err)
+	require.Equal(t, []string{otherCommentID}, commentIDs(*comments))
+
+	require.ErrorIs(t, b.Posts.DeletePost(ctx, postID), apperr.ErrPostNotFound)
+}
+
+func testCommentsBatch(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	full := savePost(t, b)
+	exact := savePost(t, b)
+	empty := savePost(t, b)
+
+	var fullIDs []string
+	for i := 0; i < 4; i++ {
+		fullIDs = append(fullIDs, saveComment(t, b, full, nil))
+	}
+	var exactIDs []string
+	for i := 0; i < 2; i++ {
+		exactIDs = append(exactIDs, saveComment(t, b, exact, nil))
+	}
+
+	pages, err := b.Comments.GetCommentsBatch(ctx, 2, []string{full, exact, empty, "missing"})
+	require.NoError(t, err)
+	require.Len(t, pages, 4)
+
+	require.Equal(t, fullIDs[:2], commentIDs(pages[full].Comments))
+	require.True(t, pages[full].HasNextPage)
+	require.Equal(t, fullIDs[1], pages[full].EndCursor)
+
+	require.Equal(t, exactIDs, commentIDs(pages[exact].Comments))
+	require.False(t, pages[exact].HasNextPage)
+
+	for _, id := range []string{empty, "missing"} {
+		require.Empty(t, pages[id].Comments)
+		require.False(t, pages[id].HasNextPage)
+		require.Empty(t, pages[id].EndCursor)
+	}
+
+	// первая страница пакета совпадает с GetComments
+	comments, hasNext, endCursor, err := b.Comments.GetComments(ctx, ptr(int32(2)), nil, full)
+	require.NoError(t, err)
+	require.Equal(t, commentIDs(pages[full].Comments), commentIDs(*comments))
+	require.Equal(t, pages[full].HasNextPage, hasNext)
+	require.Equal(t, pages[full].EndCursor, endCursor)
+
+	pages, err = b.Comments.GetCommentsBatch(ctx, 0, []string{full})
+	require.NoError(t, err)
+	require.Empty(t, pages[full].Comments)
+	require.False(t, pages[full].HasNextPage)
+}
+
+func testConcurrentWrites(t *testing.T, b Backend, _ *Clock) {
+	ctx := context.Background()
+	postID := savePost(t, b)
+	rootID := saveComment(t, b, postID, nil)
+
+	const (
+		writers  = 8
+		comments = 10
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*comments)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < comments; i++ {
+				c := &model.Comment{PostID: postID, Content: "comment"}
+				if (w+i)%2 == 0 {
+					c.ParentID = &rootID
+				}
+				if _, _, err := b.Comments.SaveComment(ctx, c); err != nil {
+					errs <- err
+				}
+				// чтения вперемешку с записью
+				if _, _, _, err := b.Comments.GetComments(ctx, ptr(int32(5)), nil, postID); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	count, err := b.Comments.CountComments(ctx, postID)
+	require.NoError(t, err)
+	require.Equal(t, int32(writers*comments+1), count)
+
+	got := pageAll(t, 7, func(after *string) ([]string, bool, string, error) {
+		page, hasNext, endCursor, err := b.Comments.GetComments(ctx, ptr(int32(7)), after, postID)
+		if err != nil {
+			return nil, false, "", err
+		}
+		return commentIDs(*page), hasNext, endCursor, nil
+	})
+	require.Len(t, got, writers*comments+1)
+
+	seen := make(map[string]bool, len(got))
+	for _, id := range got {
+		require.False(t, seen[id], "comment %s returned twice", id)
+		seen[id] = true
+	}
+}